@@ -21,20 +21,23 @@ import (
 )
 
 type ACL struct {
-	UUID       string
-	Action     string
-	Direction  string
-	Match      string
-	Priority   int
-	Log        bool
-	ExternalID map[interface{}]interface{}
+	UUID       string                      `ovsdb:"_uuid"`
+	Action     string                      `ovsdb:"action"`
+	Direction  string                      `ovsdb:"direction"`
+	Match      string                      `ovsdb:"match"`
+	Priority   int                         `ovsdb:"priority"`
+	Log        bool                        `ovsdb:"log"`
+	Meter      string                      `ovsdb:"meter"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
 }
 
-func (odbi *ovnDBImp) getACLUUIDByRow(lsw, table string, row OVNRow) (string, error) {
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
-	for _, drows := range odbi.cache[tableLogicalSwitch] {
-		if rlsw, ok := drows.Fields["name"].(string); ok && rlsw == lsw {
+// getACLUUIDByRow looks up an acl attached to the named entity of table,
+// which is either a Logical_Switch or a Port_Group.
+func (odbi *ovnDBImp) getACLUUIDByRow(table, entity string, row OVNRow) (string, error) {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for _, drows := range odbi.cache[table] {
+		if rname, ok := drows.Fields["name"].(string); ok && rname == entity {
 			acls := drows.Fields["acls"]
 			if acls != nil {
 				switch acls.(type) {
@@ -116,7 +119,7 @@ func (odbi *ovnDBImp) getACLUUIDByRow(lsw, table string, row OVNRow) (string, er
 	return "", ErrorNotFound
 }
 
-func (odbi *ovnDBImp) aclAddImp(lsw, direct, match, action string, priority int, external_ids map[string]string, logflag bool, meter string) (*OvnCommand, error) {
+func (odbi *ovnDBImp) aclAddImp(table, entity, direct, match, action string, priority int, external_ids map[string]string, logflag bool, meter string) (*OvnCommand, error) {
 	namedUUID, err := newRowUUID()
 	if err != nil {
 		return nil, err
@@ -134,7 +137,7 @@ func (odbi *ovnDBImp) aclAddImp(lsw, direct, match, action string, priority int,
 		row["external_ids"] = oMap
 	}
 
-	_, err = odbi.getACLUUIDByRow(lsw, tableACL, row)
+	_, err = odbi.getACLUUIDByRow(table, entity, row)
 	switch err {
 	case ErrorNotFound:
 		break
@@ -144,10 +147,22 @@ func (odbi *ovnDBImp) aclAddImp(lsw, direct, match, action string, priority int,
 		return nil, err
 	}
 
-	row["action"] = action
-	row["log"] = logflag
+	acl := &ACL{
+		Action:     action,
+		Direction:  direct,
+		Match:      match,
+		Priority:   priority,
+		Log:        logflag,
+		ExternalID: toModelMap(external_ids),
+	}
+	columns := []string{"action", "direction", "match", "priority", "log", "external_ids"}
 	if logflag {
-		row["meter"] = meter
+		acl.Meter = meter
+		columns = append(columns, "meter")
+	}
+	row, err = odbi.encodeRow(tableACL, acl, columns...)
+	if err != nil {
+		return nil, err
 	}
 	insertOp := libovsdb.Operation{
 		Op:       opInsert,
@@ -162,12 +177,12 @@ func (odbi *ovnDBImp) aclAddImp(lsw, direct, match, action string, priority int,
 		return nil, err
 	}
 	mutation := libovsdb.NewMutation("acls", opInsert, mutateSet)
-	condition := libovsdb.NewCondition("name", "==", lsw)
+	condition := libovsdb.NewCondition("name", "==", entity)
 
 	// simple mutate operation
 	mutateOp := libovsdb.Operation{
 		Op:        opMutate,
-		Table:     tableLogicalSwitch,
+		Table:     table,
 		Mutations: []interface{}{mutation},
 		Where:     []interface{}{condition},
 	}
@@ -175,7 +190,7 @@ func (odbi *ovnDBImp) aclAddImp(lsw, direct, match, action string, priority int,
 	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
 }
 
-func (odbi *ovnDBImp) aclDelImp(lsw, direct, match string, priority int, external_ids map[string]string) (*OvnCommand, error) {
+func (odbi *ovnDBImp) aclDelImp(table, entity, direct, match string, priority int, external_ids map[string]string) (*OvnCommand, error) {
 	row := make(OVNRow)
 
 	wherecondition := []interface{}{}
@@ -199,7 +214,7 @@ func (odbi *ovnDBImp) aclDelImp(lsw, direct, match string, priority int, externa
 		row["external_ids"] = oMap
 	}
 
-	aclUUID, err := odbi.getACLUUIDByRow(lsw, tableACL, row)
+	aclUUID, err := odbi.getACLUUIDByRow(table, entity, row)
 	if err != nil {
 		return nil, err
 	}
@@ -213,12 +228,12 @@ func (odbi *ovnDBImp) aclDelImp(lsw, direct, match string, priority int, externa
 	}
 
 	mutation := libovsdb.NewMutation("acls", opDelete, libovsdb.UUID{aclUUID})
-	condition := libovsdb.NewCondition("name", "==", lsw)
+	condition := libovsdb.NewCondition("name", "==", entity)
 
 	// Simple mutate operation
 	mutateOp := libovsdb.Operation{
 		Op:        opMutate,
-		Table:     tableLogicalSwitch,
+		Table:     table,
 		Mutations: []interface{}{mutation},
 		Where:     []interface{}{condition},
 	}
@@ -226,27 +241,31 @@ func (odbi *ovnDBImp) aclDelImp(lsw, direct, match string, priority int, externa
 	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
 }
 
-func (odbi *ovnDBImp) RowToACL(uuid string) *ACL {
-	acl := &ACL{
-		UUID:       uuid,
-		Action:     odbi.cache[tableACL][uuid].Fields["action"].(string),
-		Direction:  odbi.cache[tableACL][uuid].Fields["direction"].(string),
-		Match:      odbi.cache[tableACL][uuid].Fields["match"].(string),
-		Priority:   odbi.cache[tableACL][uuid].Fields["priority"].(int),
-		Log:        odbi.cache[tableACL][uuid].Fields["log"].(bool),
-		ExternalID: odbi.cache[tableACL][uuid].Fields["external_ids"].(libovsdb.OvsMap).GoMap,
+func (odbi *ovnDBImp) RowToACL(uuid string) (*ACL, error) {
+	acl := &ACL{}
+	if err := decodeRow(uuid, odbi.cache[tableACL][uuid].Fields, acl); err != nil {
+		return nil, err
 	}
-	return acl
+	return acl, nil
 }
 
 // Get all acl by lswitch
 func (odbi *ovnDBImp) GetACLsBySwitch(lsw string) []*ACL {
+	return odbi.getACLsImp(tableLogicalSwitch, lsw)
+}
+
+// Get all acl by port group
+func (odbi *ovnDBImp) GetACLsByPortGroup(group string) []*ACL {
+	return odbi.getACLsImp(tablePortGroup, group)
+}
+
+func (odbi *ovnDBImp) getACLsImp(table, entity string) []*ACL {
 	//TODO: should be improvement here, when have lots of acls.
 	acllist := make([]*ACL, 0, 0)
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
-	for _, drows := range odbi.cache[tableLogicalSwitch] {
-		if rlsw, ok := drows.Fields["name"].(string); ok && rlsw == lsw {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for _, drows := range odbi.cache[table] {
+		if rname, ok := drows.Fields["name"].(string); ok && rname == entity {
 			acls := drows.Fields["acls"]
 			if acls != nil {
 				switch acls.(type) {
@@ -254,15 +273,17 @@ func (odbi *ovnDBImp) GetACLsBySwitch(lsw string) []*ACL {
 					if as, ok := acls.(libovsdb.OvsSet); ok {
 						for _, a := range as.GoSet {
 							if va, ok := a.(libovsdb.UUID); ok {
-								ta := odbi.RowToACL(va.GoUUID)
-								acllist = append(acllist, ta)
+								if ta, err := odbi.RowToACL(va.GoUUID); err == nil {
+									acllist = append(acllist, ta)
+								}
 							}
 						}
 					}
 				case libovsdb.UUID:
 					if va, ok := acls.(libovsdb.UUID); ok {
-						ta := odbi.RowToACL(va.GoUUID)
-						acllist = append(acllist, ta)
+						if ta, err := odbi.RowToACL(va.GoUUID); err == nil {
+							acllist = append(acllist, ta)
+						}
 					}
 				}
 			}
@@ -21,28 +21,25 @@ import (
 )
 
 type AddressSet struct {
-	UUID       string
-	Name       string
-	Addresses  []string
-	ExternalID map[interface{}]interface{}
+	UUID       string                      `ovsdb:"_uuid"`
+	Name       string                      `ovsdb:"name"`
+	Addresses  []string                    `ovsdb:"addresses"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
 }
 
 func (odbi *ovnDBImp) ASUpdate(name string, addrs []string, external_ids map[string]string) (*OvnCommand, error) {
-	row := make(OVNRow)
-	row["name"] = name
-	addresses, err := libovsdb.NewOvsSet(addrs)
+	columns := []string{"name", "addresses"}
+	if external_ids != nil {
+		columns = append(columns, "external_ids")
+	}
+	row, err := odbi.encodeRow(tableAddressSet, &AddressSet{
+		Name:       name,
+		Addresses:  addrs,
+		ExternalID: toModelMap(external_ids),
+	}, columns...)
 	if err != nil {
 		return nil, err
 	}
-
-	row["addresses"] = addresses
-	if external_ids != nil {
-		oMap, err := libovsdb.NewOvsMap(external_ids)
-		if err != nil {
-			return nil, err
-		}
-		row["external_ids"] = oMap
-	}
 	condition := libovsdb.NewCondition("name", "==", name)
 	updateOp := libovsdb.Operation{
 		Op:    opUpdate,
@@ -63,18 +60,14 @@ func (odbi *ovnDBImp) ASAdd(name string, addrs []string, external_ids map[string
 		return nil, ErrorExist
 	}
 
-	if external_ids != nil {
-		oMap, err := libovsdb.NewOvsMap(external_ids)
-		if err != nil {
-			return nil, err
-		}
-		row["external_ids"] = oMap
-	}
-	addresses, err := libovsdb.NewOvsSet(addrs)
+	row, err := odbi.encodeRow(tableAddressSet, &AddressSet{
+		Name:       name,
+		Addresses:  addrs,
+		ExternalID: toModelMap(external_ids),
+	})
 	if err != nil {
 		return nil, err
 	}
-	row["addresses"] = addresses
 	insertOp := libovsdb.Operation{
 		Op:    opInsert,
 		Table: tableAddressSet,
@@ -105,34 +98,23 @@ func (odbi *ovnDBImp) ASDel(name string) (*OvnCommand, error) {
 	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
 }
 
+func (odbi *ovnDBImp) RowToAddressSet(uuid string) (*AddressSet, error) {
+	as := &AddressSet{Addresses: []string{}}
+	if err := decodeRow(uuid, odbi.cache[tableAddressSet][uuid].Fields, as); err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
 // Get all addressset
 func (odbi *ovnDBImp) GetAddressSets() []*AddressSet {
 	adlist := make([]*AddressSet, 0, 0)
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
-	for uuid, drows := range odbi.cache[tableAddressSet] {
-		ta := &AddressSet{
-			UUID:       uuid,
-			Name:       drows.Fields["name"].(string),
-			ExternalID: drows.Fields["external_ids"].(libovsdb.OvsMap).GoMap,
-		}
-		addresses := []string{}
-		as := drows.Fields["addresses"]
-		switch as.(type) {
-		case libovsdb.OvsSet:
-			//TODO: is it possible return interface type directly instead of GoSet
-			if goset, ok := drows.Fields["addresses"].(libovsdb.OvsSet); ok {
-				for _, i := range goset.GoSet {
-					addresses = append(addresses, i.(string))
-				}
-			}
-		case string:
-			if v, ok := drows.Fields["addresses"].(string); ok {
-				addresses = append(addresses, v)
-			}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid := range odbi.cache[tableAddressSet] {
+		if as, err := odbi.RowToAddressSet(uuid); err == nil {
+			adlist = append(adlist, as)
 		}
-		ta.Addresses = addresses
-		adlist = append(adlist, ta)
 	}
 	return adlist
 }
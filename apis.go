@@ -17,13 +17,18 @@
 package goovn
 
 import (
+	"context"
+
 	"github.com/unistack-org/libovsdb"
 )
 
 type OvnCommand struct {
 	Operations []libovsdb.Operation
 	Exe        Execution
-	Results    [][]map[string]interface{}
+	// Results holds the rows returned by each operation once the command is
+	// executed, the selected rows or, for an insert, a row with the _uuid
+	// of the new row.
+	Results [][]map[string]interface{}
 }
 
 type Execution interface {
@@ -51,6 +56,28 @@ type OVNDBApi interface {
 	ACLAdd(lsw, direct, match, action string, priority int, external_ids map[string]string, logflag bool, meter string) (*OvnCommand, error)
 	// Delete acl
 	ACLDel(lsw, direct, match string, priority int, external_ids map[string]string) (*OvnCommand, error)
+	// Add ACL to port group
+	PGACLAdd(group, direct, match, action string, priority int, external_ids map[string]string, logflag bool, meter string) (*OvnCommand, error)
+	// Delete acl from port group
+	PGACLDel(group, direct, match string, priority int, external_ids map[string]string) (*OvnCommand, error)
+	// Add qos rule to lswitch, action and bandwidth are optional
+	QoSAdd(lsw string, direction string, priority int, match string, action map[string]int, bandwidth map[string]int, external_ids map[string]string) (*OvnCommand, error)
+	// Delete qos rules from lswitch, empty direction and match or negative priority match any
+	QoSDel(lsw string, direction string, priority int, match string) (*OvnCommand, error)
+	// Add meter with given name, unit and bands, used to rate limit acl logging
+	MeterAdd(name string, unit string, bands []*MeterBand, external_ids map[string]string) (*OvnCommand, error)
+	// Delete meter with given name
+	MeterDel(name string) (*OvnCommand, error)
+	// Add dns records and attach them to lswitch
+	DNSAdd(lsw string, records map[string]string, external_ids map[string]string) (*OvnCommand, error)
+	// Delete dns with given uuid and detach it from all lswitches
+	DNSDel(uuid string) (*OvnCommand, error)
+	// Replace records of dns with given uuid
+	DNSSetRecords(uuid string, records map[string]string) (*OvnCommand, error)
+	// Attach existing dns to lswitch
+	LSWAttachDNS(lsw string, uuid string) (*OvnCommand, error)
+	// Detach dns from lswitch, dns is deleted when detached from its last lswitch
+	LSWDetachDNS(lsw string, uuid string) (*OvnCommand, error)
 	// Update address set
 	ASUpdate(name string, addrs []string, external_ids map[string]string) (*OvnCommand, error)
 	// Add addressset
@@ -65,17 +92,31 @@ type OVNDBApi interface {
 	LRPAdd(lr string, lrp string, mac string, network []string, peer string, external_ids map[string]string) (*OvnCommand, error)
 	// Delete LRP with given name on given lr
 	LRPDel(lr string, lrp string) (*OvnCommand, error)
+	// Add NAT rule of given type to lr, logicalPort and externalMac are given together for distributed dnat_and_snat, empty otherwise
+	LRNATAdd(lr string, ntype string, externalIp string, logicalIp string, logicalPort string, externalMac string, external_ids map[string]string) (*OvnCommand, error)
+	// Delete NAT rules from lr, optionally filtered by type and ip
+	LRNATDel(lr string, ntype string, ip ...string) (*OvnCommand, error)
+	// Add static route to lr, output_port and policy are optional
+	LRSRAdd(lr string, ip_prefix string, nexthop string, output_port string, policy string, external_ids map[string]string) (*OvnCommand, error)
+	// Delete static routes from lr, optionally filtered by ip_prefix and nexthop
+	LRSRDel(lr string, ip_prefix string, nexthop string) (*OvnCommand, error)
+	// Schedule lrp on chassis with given priority
+	LRPSetGatewayChassis(lrp string, chassis string, priority int) (*OvnCommand, error)
+	// Unschedule lrp from chassis
+	LRPDelGatewayChassis(lrp string, chassis string) (*OvnCommand, error)
+	// Schedule lrp on exactly the given chassis, with priorities descending in list order
+	LRPRebalanceGatewayChassis(lrp string, chassis []string) (*OvnCommand, error)
 	// Add LB
 	LBAdd(name string, vipPort string, protocol string, addrs []string) (*OvnCommand, error)
 	// Delete LB with given name
 	LBDel(name string) (*OvnCommand, error)
 	// Update existing LB
 	LBUpdate(name string, vipPort string, protocol string, addrs []string) (*OvnCommand, error)
-	// Set dhcp4_options uuid on lsp
+	// Set dhcp4_options uuid on lsp, or the NamedUUID of a command of the same transaction
 	LSPSetDHCPv4Options(lsp string, options string) (*OvnCommand, error)
 	// Get dhcp4_options from lsp
 	LSPGetDHCPv4Options(lsp string) (*DHCPOptions, error)
-	// Set dhcp6_options uuid on lsp
+	// Set dhcp6_options uuid on lsp, or the NamedUUID of a command of the same transaction
 	LSPSetDHCPv6Options(lsp string, options string) (*OvnCommand, error)
 	// Get dhcp6_options from lsp
 	LSPGetDHCPv6Options(lsp string) (*DHCPOptions, error)
@@ -90,8 +131,51 @@ type OVNDBApi interface {
 	// Del dhcp options via provided external_ids
 	DelDHCPOptions(uuid string) (*OvnCommand, error)
 
+	// Add port group with given name and lsp uuids
+	PGAdd(group string, ports []string, external_ids map[string]string) (*OvnCommand, error)
+	// Replace ports and external_ids of existing port group
+	PGUpdate(group string, ports []string, external_ids map[string]string) (*OvnCommand, error)
+	// Delete port group with given name
+	PGDel(group string) (*OvnCommand, error)
+	// Add lsp uuid to port group
+	PGAddPort(group string, port string) (*OvnCommand, error)
+	// Remove lsp uuid from port group
+	PGRemovePort(group string, port string) (*OvnCommand, error)
+
+	// Generic commands on any table, as ovn-nbctl list/find/get/set/add/
+	// remove/clear. A record is the uuid or the name of a row. Values are Go
+	// values converted to the type of the column: an atom or a slice for a
+	// set, a Go map for a map. Selected rows are in Results once executed.
+	// Select all rows of table
+	List(table string) (*OvnCommand, error)
+	// Select the rows of table matching all conditions
+	Find(table string, conditions ...Condition) (*OvnCommand, error)
+	// Select column of record
+	Get(table, record, column string) (*OvnCommand, error)
+	// Set the columns of record to the given values
+	Set(table, record string, values map[string]interface{}) (*OvnCommand, error)
+	// Add values to the set or map column of record
+	Add(table, record, column string, values interface{}) (*OvnCommand, error)
+	// Remove values from the set or map column of record, keys or pairs for a map
+	Remove(table, record, column string, values interface{}) (*OvnCommand, error)
+	// Empty the set or map columns of record
+	Clear(table, record string, columns ...string) (*OvnCommand, error)
+
+	// Models are structs with ovsdb tags mapping their fields to columns,
+	// see model.go.
+	// Insert a row made of the columns of model
+	InsertModel(table string, model interface{}) (*OvnCommand, error)
+	// Set the given columns of record, all of them if none, from model
+	UpdateModel(table, record string, model interface{}, columns ...string) (*OvnCommand, error)
+	// Get all rows of table into models, a pointer to a slice of models
+	GetModels(table string, models interface{}) error
+	// Get record, a uuid or a name, into model
+	GetModel(table, record string, model interface{}) error
+
 	// Exec command, support mul-commands in one transaction.
 	Execute(cmds ...*OvnCommand) error
+	// Exec command like Execute, aborting it when ctx is done
+	ExecuteContext(ctx context.Context, cmds ...*OvnCommand) error
 
 	// Get all logical switches
 	GetLogicSwitches() []*LogicalSwitch
@@ -99,9 +183,30 @@ type OVNDBApi interface {
 	GetLogicPortsBySwitch(lsw string) ([]*LogicalSwitchPort, error)
 	// Get all lrp by lr
 	GetLogicalRouterPortsByRouter(lr string) ([]*LogicalRouterPort, error)
+	// Get all nat rules by lr
+	GetLRNATs(lr string) ([]*NAT, error)
+	// Get all static routes by lr
+	GetLogicalRouterStaticRoutes(lr string) ([]*LogicalRouterStaticRoute, error)
+	// Get all gateway chassis by lrp
+	GetGatewayChassis(lrp string) ([]*GatewayChassis, error)
 
 	// Get all acl by lswitch
 	GetACLsBySwitch(lsw string) []*ACL
+	// Get all acl by port group
+	GetACLsByPortGroup(group string) []*ACL
+	// Get all qos rules by lswitch
+	GetQoSBySwitch(lsw string) ([]*QoS, error)
+	// Get all meters
+	GetMeters() []*Meter
+	// Get dns with given uuid
+	GetDNS(uuid string) (*DNS, error)
+	// Get all dns by lswitch
+	GetDNSBySwitch(lsw string) ([]*DNS, error)
+
+	// Get all port groups
+	GetPortGroups() []*PortGroup
+	// Get port group with given name
+	GetPortGroupByName(group string) (*PortGroup, error)
 
 	GetAddressSets() []*AddressSet
 	GetASByName(name string) *AddressSet
@@ -112,8 +217,61 @@ type OVNDBApi interface {
 	// Get LR with given name
 	GetLogicalRouters() []*LogicalRouter
 	SetCallBack(callback OVNSignal)
+	// Receive the changes selected by filter until cancel is called, any
+	// number of subscriptions can be made
+	Subscribe(filter EventFilter) (events <-chan Event, cancel func())
+	// Version of the schema of the database, as "5.16.0"
+	SchemaVersion() string
+	// Cancel the monitor and disconnect, the client can not be used afterwards
+	Close() error
 }
 
+// South bound api set, read mostly
+type OVNSBApi interface {
+	// Delete stale chassis with given name
+	ChassisDel(name string) (*OvnCommand, error)
+
+	// Exec command, support mul-commands in one transaction.
+	Execute(cmds ...*OvnCommand) error
+	// Exec command like Execute, aborting it when ctx is done
+	ExecuteContext(ctx context.Context, cmds ...*OvnCommand) error
+
+	// Get all chassis
+	GetChassis() []*Chassis
+	// Get chassis with given name
+	GetChassisByName(name string) (*Chassis, error)
+	// Get all encaps by chassis
+	GetEncapsByChassis(name string) ([]*Encap, error)
+	// Get all port bindings
+	GetPortBindings() []*PortBinding
+	// Get port binding of logical port
+	GetPortBindingByLogicalPort(lport string) (*PortBinding, error)
+	// Get all port bindings bound to chassis
+	GetPortBindingsByChassis(name string) ([]*PortBinding, error)
+	// Get all datapath bindings
+	GetDatapathBindings() []*DatapathBinding
+	// Get all mac bindings
+	GetMACBindings() []*MACBinding
+	SetCallBack(callback OVNSBSignal)
+	// Receive the changes selected by filter until cancel is called, any
+	// number of subscriptions can be made
+	Subscribe(filter EventFilter) (events <-chan Event, cancel func())
+	// Version of the schema of the database, as "5.16.0"
+	SchemaVersion() string
+	// Cancel the monitor and disconnect, the client can not be used afterwards
+	Close() error
+}
+
+// OVNSignal is notified of the changes of the northbound database, once they
+// are in the cache and in the order they were received. Callbacks run
+// without the cache locked, so they can use the getters, but they hold up
+// the following updates of the connection and must not wait for a
+// transaction.
+//
+// As before update callbacks were added, a modified row is signaled again
+// with On*Create, carrying its new content, unless the callback also
+// implements OVNUpdateSignal. Such callbacks must treat a create of a known
+// uuid as a modification of its row.
 type OVNSignal interface {
 	OnLogicalSwitchCreate(ls *LogicalSwitch)
 	OnLogicalSwitchDelete(ls *LogicalSwitch)
@@ -134,6 +292,60 @@ type OVNSignal interface {
 	OnDHCPOptionsDelete(dhcp *DHCPOptions)
 }
 
+// OVNUpdateSignal is implemented by an OVNSignal interested in the
+// modification of rows, with their content before and after it, instead of
+// a create of the modified row.
+type OVNUpdateSignal interface {
+	OnLogicalSwitchUpdate(old, new *LogicalSwitch)
+	OnLogicalPortUpdate(old, new *LogicalSwitchPort)
+	OnLogicalRouterUpdate(old, new *LogicalRouter)
+	OnLogicalRouterPortUpdate(old, new *LogicalRouterPort)
+	OnACLUpdate(old, new *ACL)
+	OnDHCPOptionsUpdate(old, new *DHCPOptions)
+}
+
+// OVNQoSSignal is implemented by an OVNSignal interested in the QoS rules
+type OVNQoSSignal interface {
+	OnQoSCreate(qos *QoS)
+	OnQoSDelete(qos *QoS)
+	OnQoSUpdate(old, new *QoS)
+}
+
+// OVNSBSignal is the OVNSignal of the southbound database. A modified row
+// is signaled with On*Create unless the callback also implements
+// OVNSBUpdateSignal.
+type OVNSBSignal interface {
+	OnChassisCreate(chassis *Chassis)
+	OnChassisDelete(chassis *Chassis)
+
+	OnPortBindingCreate(pb *PortBinding)
+	OnPortBindingDelete(pb *PortBinding)
+
+	OnDatapathBindingCreate(dp *DatapathBinding)
+	OnDatapathBindingDelete(dp *DatapathBinding)
+
+	OnMACBindingCreate(mb *MACBinding)
+	OnMACBindingDelete(mb *MACBinding)
+}
+
+// OVNSBUpdateSignal is the OVNUpdateSignal of an OVNSBSignal
+type OVNSBUpdateSignal interface {
+	OnChassisUpdate(old, new *Chassis)
+	OnPortBindingUpdate(old, new *PortBinding)
+	OnDatapathBindingUpdate(old, new *DatapathBinding)
+	OnMACBindingUpdate(old, new *MACBinding)
+}
+
+// OVNTableSignal is implemented by an OVNSignal or OVNSBSignal interested in
+// the changes of every cached table, including those without a dedicated
+// callback. old is nil for a created row and new is nil for a deleted one.
+// Rows of tables with a model, as Port_Group or NAT, are given as the
+// pointer returned by its getters, as *PortGroup or *NAT, and the others as
+// a libovsdb.Row.
+type OVNTableSignal interface {
+	OnTableChange(table, uuid string, old, new interface{})
+}
+
 // Notifier
 type OVNNotifier interface {
 	Update(context interface{}, tableUpdates libovsdb.TableUpdates)
@@ -146,3 +358,72 @@ type OVNNotifier interface {
 func (ocmd *OvnCommand) Execute() error {
 	return ocmd.Exe.Execute()
 }
+
+func (ocmd *OvnCommand) setResults(replies []libovsdb.OperationResult) {
+	ocmd.Results = make([][]map[string]interface{}, len(ocmd.Operations))
+	for i, op := range ocmd.Operations {
+		switch op.Op {
+		case opInsert:
+			ocmd.Results[i] = []map[string]interface{}{{"_uuid": replies[i].UUID}}
+		case opSelect:
+			for _, row := range replies[i].Rows {
+				ocmd.Results[i] = append(ocmd.Results[i], row)
+			}
+		}
+	}
+}
+
+// UUIDs returns the real uuid of every row inserted by the executed
+// command, by the named uuid of its insert operation.
+func (ocmd *OvnCommand) UUIDs() map[string]string {
+	uuids := make(map[string]string)
+	for i, op := range ocmd.Operations {
+		if op.Op != opInsert || op.UUIDName == "" || i >= len(ocmd.Results) {
+			continue
+		}
+		if uuid := resultUUID(ocmd.Results[i]); uuid != "" {
+			uuids[op.UUIDName] = uuid
+		}
+	}
+	return uuids
+}
+
+// UUID returns the uuid of the row inserted by the executed command, the
+// last one for commands inserting several rows, like the meter after its
+// bands. ErrorNotFound is returned if the command inserted nothing or was
+// not executed yet.
+func (ocmd *OvnCommand) UUID() (string, error) {
+	for i := len(ocmd.Operations) - 1; i >= 0; i-- {
+		if ocmd.Operations[i].Op != opInsert {
+			continue
+		}
+		if i < len(ocmd.Results) {
+			if uuid := resultUUID(ocmd.Results[i]); uuid != "" {
+				return uuid, nil
+			}
+		}
+		break
+	}
+	return "", ErrorNotFound
+}
+
+// NamedUUID returns the named uuid of the row the command inserts, chosen
+// like UUID. Commands taking a uuid accept it in place of a real one when
+// they are executed in the same transaction, after this command, e.g. to
+// create dhcp options and set them on a port at once.
+func (ocmd *OvnCommand) NamedUUID() (string, error) {
+	for i := len(ocmd.Operations) - 1; i >= 0; i-- {
+		if ocmd.Operations[i].Op == opInsert && ocmd.Operations[i].UUIDName != "" {
+			return ocmd.Operations[i].UUIDName, nil
+		}
+	}
+	return "", ErrorNotFound
+}
+
+func resultUUID(rows []map[string]interface{}) string {
+	if len(rows) != 1 {
+		return ""
+	}
+	uuid, _ := rows[0]["_uuid"].(libovsdb.UUID)
+	return uuid.GoUUID
+}
@@ -0,0 +1,109 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"github.com/unistack-org/libovsdb"
+)
+
+type Chassis struct {
+	UUID       string                      `ovsdb:"_uuid"`
+	Name       string                      `ovsdb:"name"`
+	Hostname   string                      `ovsdb:"hostname"`
+	Encaps     []string                    `ovsdb:"encaps"`
+	NbCfg      int                         `ovsdb:"nb_cfg"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+type Encap struct {
+	UUID        string                      `ovsdb:"_uuid"`
+	Type        string                      `ovsdb:"type"`
+	IP          string                      `ovsdb:"ip"`
+	ChassisName string                      `ovsdb:"chassis_name"`
+	Options     map[interface{}]interface{} `ovsdb:"options"`
+}
+
+func (odbi *ovnDBImp) chassisDelImp(name string) (*OvnCommand, error) {
+	condition := libovsdb.NewCondition("name", "==", name)
+	deleteOp := libovsdb.Operation{
+		Op:    opDelete,
+		Table: tableChassis,
+		Where: []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{deleteOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) RowToChassis(uuid string) (*Chassis, error) {
+	chassis := &Chassis{}
+	if err := decodeRow(uuid, odbi.cache[tableChassis][uuid].Fields, chassis); err != nil {
+		return nil, err
+	}
+	return chassis, nil
+}
+
+func (odbi *ovnDBImp) RowToEncap(uuid string) (*Encap, error) {
+	encap := &Encap{}
+	if err := decodeRow(uuid, odbi.cache[tableEncap][uuid].Fields, encap); err != nil {
+		return nil, err
+	}
+	return encap, nil
+}
+
+// Get all chassis
+func (odbi *ovnDBImp) GetChassis() []*Chassis {
+	var chassislist = []*Chassis{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid := range odbi.cache[tableChassis] {
+		if chassis, err := odbi.RowToChassis(uuid); err == nil {
+			chassislist = append(chassislist, chassis)
+		}
+	}
+	return chassislist
+}
+
+// Get chassis by name
+func (odbi *ovnDBImp) GetChassisByName(name string) (*Chassis, error) {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid, drows := range odbi.cache[tableChassis] {
+		if chName, ok := drows.Fields["name"].(string); ok && chName == name {
+			return odbi.RowToChassis(uuid)
+		}
+	}
+	return nil, ErrorNotFound
+}
+
+// Get all encaps by chassis
+func (odbi *ovnDBImp) GetEncapsByChassis(name string) ([]*Encap, error) {
+	var encaplist = []*Encap{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	_, encaps, err := odbi.getRowRefsByName(tableChassis, name, "encaps")
+	if err != nil {
+		return nil, err
+	}
+	for _, encap := range encaps {
+		if _, ok := odbi.cache[tableEncap][encap]; ok {
+			if e, err := odbi.RowToEncap(encap); err == nil {
+				encaplist = append(encaplist, e)
+			}
+		}
+	}
+	return encaplist, nil
+}
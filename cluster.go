@@ -0,0 +1,209 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+const (
+	defaultAddress string = "127.0.0.1"
+	defaultPort    int    = 6640
+)
+
+const (
+	serverDB       string = "_Server"
+	tableDatabase  string = "Database"
+	modelClustered string = "clustered"
+)
+
+// remote is one member of a database, in the ovsdb remote format
+// unix:<file>, tcp:<host>:<port> or ssl:<host>:<port>
+type remote struct {
+	protocol string
+	// address is the socket file for unix and the host otherwise
+	address string
+	port    int
+}
+
+// host defaults to the local host like libovsdb did
+func (r remote) host() string {
+	if r.address == "" {
+		return defaultAddress
+	}
+	return r.address
+}
+
+func (r remote) target() string {
+	port := r.port
+	if port <= 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(r.host(), strconv.Itoa(port))
+}
+
+func (r remote) String() string {
+	if r.protocol == UNIX {
+		return r.protocol + ":" + r.address
+	}
+	return r.protocol + ":" + net.JoinHostPort(r.address, strconv.Itoa(r.port))
+}
+
+// parseRemotes parses a comma separated list of remotes such as
+// "tcp:10.0.0.1:6641,ssl:[fd00::1]:6641".
+func parseRemotes(remotes string) ([]remote, error) {
+	var ret []remote
+	for _, s := range strings.Split(remotes, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		strs := strings.SplitN(s, ":", 2)
+		if len(strs) != 2 || strs[1] == "" {
+			return nil, fmt.Errorf("invalid remote [%s]", s)
+		}
+		switch strs[0] {
+		case UNIX:
+			ret = append(ret, remote{protocol: UNIX, address: strs[1]})
+		case TCP, SSL:
+			host, portstr, err := net.SplitHostPort(strs[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid remote [%s]: %v", s, err)
+			}
+			port, err := strconv.Atoi(portstr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid remote [%s]: %v", s, err)
+			}
+			ret = append(ret, remote{protocol: strs[0], address: host, port: port})
+		default:
+			return nil, fmt.Errorf("the protocol [%s] is not supported", strs[0])
+		}
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no remote in [%s]", remotes)
+	}
+	return ret, nil
+}
+
+// configRemotes returns the remotes of cfg, either Remotes or the single
+// remote given by Protocol, Socket, Server and Port.
+func configRemotes(cfg Config) ([]remote, error) {
+	if cfg.Remotes != "" {
+		return parseRemotes(cfg.Remotes)
+	}
+	switch cfg.Protocol {
+	case UNIX:
+		return []remote{{protocol: UNIX, address: cfg.Socket}}, nil
+	case TCP, SSL:
+		return []remote{{protocol: cfg.Protocol, address: cfg.Server, port: cfg.Port}}, nil
+	}
+	return nil, fmt.Errorf("the protocol [%s] is not supported", cfg.Protocol)
+}
+
+// serverStatus is the row of a database in the _Server database
+type serverStatus struct {
+	model     string
+	connected bool
+	leader    bool
+}
+
+// usable tells if the member can serve reads and writes. A standalone
+// server always can, a cluster member only while it is the leader of a
+// healthy cluster.
+func (status serverStatus) usable() bool {
+	return status.model != modelClustered || (status.connected && status.leader)
+}
+
+func rowToServerStatus(fields map[string]interface{}) serverStatus {
+	var status serverStatus
+	status.model, _ = fields["model"].(string)
+	status.connected, _ = fields["connected"].(bool)
+	status.leader, _ = fields["leader"].(bool)
+	return status
+}
+
+// checkLeader returns an error unless conn is usable for db. Servers
+// without a _Server database predate clustering and are always usable.
+func checkLeader(ctx context.Context, conn *ovsdbConn, db string) error {
+	if _, ok := conn.Schema(serverDB); !ok {
+		return nil
+	}
+	condition := libovsdb.NewCondition("name", "==", db)
+	selectOp := libovsdb.Operation{
+		Op:      opSelect,
+		Table:   tableDatabase,
+		Where:   []interface{}{condition},
+		Columns: []string{"model", "connected", "leader"},
+	}
+	reply, err := conn.Transact(ctx, serverDB, selectOp)
+	if err != nil {
+		return err
+	}
+	if len(reply) != 1 || reply[0].Error != "" || len(reply[0].Rows) != 1 {
+		return fmt.Errorf("database %s not found on server", db)
+	}
+	status := rowToServerStatus(reply[0].Rows[0])
+	if !status.usable() {
+		return fmt.Errorf("not the leader of a connected %s cluster", db)
+	}
+	return nil
+}
+
+// monitorServer follows the _Server row of the database, so the client
+// moves on when the member loses leadership or its cluster.
+func (odbi *ovnDBImp) monitorServer(ctx context.Context, conn *ovsdbConn) error {
+	if _, ok := conn.Schema(serverDB); !ok {
+		return nil
+	}
+	requests := map[string]libovsdb.MonitorRequest{
+		tableDatabase: {
+			Columns: []string{"name", "model", "connected", "leader"},
+			Select: libovsdb.MonitorSelect{
+				Insert: true,
+				Delete: true,
+				Modify: true,
+			}},
+	}
+	_, err := conn.Monitor(ctx, serverDB, serverDB, requests)
+	return err
+}
+
+// serverUpdate drops conn once it is no longer usable, which fails over
+// to another remote through reconnect.
+func (odbi *ovnDBImp) serverUpdate(conn *ovsdbConn, updates libovsdb.TableUpdates) {
+	for _, row := range updates.Updates[tableDatabase].Rows {
+		if name, _ := row.New.Fields["name"].(string); name != odbi.db {
+			continue
+		}
+		if !rowToServerStatus(row.New.Fields).usable() {
+			conn.Disconnect()
+		}
+	}
+}
+
+// isServerUpdate tells if the update notification belongs to the _Server
+// monitor, whose json-value is serverDB.
+func isServerUpdate(context interface{}) bool {
+	params, ok := context.([]interface{})
+	return ok && len(params) > 0 && params[0] == serverDB
+}
@@ -0,0 +1,81 @@
+package goovn
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ebay/go-ovn/ovsdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemotes(t *testing.T) {
+	remotes, err := parseRemotes("tcp:10.0.0.1:6641, ssl:[fd00::2]:6641,unix:/var/run/ovn/ovnnb_db.sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []remote{
+		{protocol: TCP, address: "10.0.0.1", port: 6641},
+		{protocol: SSL, address: "fd00::2", port: 6641},
+		{protocol: UNIX, address: "/var/run/ovn/ovnnb_db.sock"},
+	}, remotes, "test[%s]", "parse remotes")
+	assert.Equal(t, "ssl:[fd00::2]:6641", remotes[1].String(), "test[%s]", "format remote")
+
+	for _, s := range []string{"", "tcp:10.0.0.1", "tcp:10.0.0.1:port", "sctp:10.0.0.1:6641", "unix:"} {
+		_, err = parseRemotes(s)
+		assert.Equal(t, true, err != nil, "test[%s]: %s", "invalid remote rejected", s)
+	}
+}
+
+func TestServerStatus(t *testing.T) {
+	assert.Equal(t, true, serverStatus{model: "standalone"}.usable(), "test[%s]", "standalone")
+	assert.Equal(t, true, serverStatus{model: modelClustered, connected: true, leader: true}.usable(), "test[%s]", "cluster leader")
+	assert.Equal(t, false, serverStatus{model: modelClustered, connected: true}.usable(), "test[%s]", "cluster follower")
+	assert.Equal(t, false, serverStatus{model: modelClustered, leader: true}.usable(), "test[%s]", "leader cut off from cluster")
+}
+
+func TestConnectRemotes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goovn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	client := &ovnDBClient{remotes: []remote{
+		{protocol: UNIX, address: filepath.Join(dir, "nb1.sock")},
+		{protocol: UNIX, address: filepath.Join(dir, "nb2.sock")},
+	}}
+	connect := func(name string) {
+		conn, err := client.connect(context.Background(), NBDB)
+		if assert.Nil(t, err, "test[%s]", name) {
+			conn.Disconnect()
+		}
+	}
+
+	second, err := ovsdbtest.NewNBServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	if err = second.ListenUnix(client.remotes[1].address); err != nil {
+		t.Fatal(err)
+	}
+	connect("failed remote skipped")
+	assert.Equal(t, 1, client.next, "test[%s]", "failed remote skipped")
+
+	first, err := ovsdbtest.NewNBServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	if err = first.ListenUnix(client.remotes[0].address); err != nil {
+		t.Fatal(err)
+	}
+	connect("reconnect to the last remote")
+	assert.Equal(t, 1, client.next, "test[%s]", "reconnect to the last remote")
+
+	second.Close()
+	connect("last remote failed")
+	assert.Equal(t, 0, client.next, "test[%s]", "last remote failed")
+}
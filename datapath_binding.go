@@ -0,0 +1,46 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+// DatapathBinding ExternalID carries the logical-switch or logical-router
+// uuid and the name of the northbound object it was created for.
+type DatapathBinding struct {
+	UUID       string                      `ovsdb:"_uuid"`
+	TunnelKey  int                         `ovsdb:"tunnel_key"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+func (odbi *ovnDBImp) RowToDatapathBinding(uuid string) (*DatapathBinding, error) {
+	dp := &DatapathBinding{}
+	if err := decodeRow(uuid, odbi.cache[tableDatapathBinding][uuid].Fields, dp); err != nil {
+		return nil, err
+	}
+	return dp, nil
+}
+
+// Get all datapath bindings
+func (odbi *ovnDBImp) GetDatapathBindings() []*DatapathBinding {
+	var dplist = []*DatapathBinding{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid := range odbi.cache[tableDatapathBinding] {
+		if dp, err := odbi.RowToDatapathBinding(uuid); err == nil {
+			dplist = append(dplist, dp)
+		}
+	}
+	return dplist
+}
@@ -21,47 +21,41 @@ import (
 )
 
 type DHCPOptions struct {
-	UUID       string
-	CIDR       string
-	Options    map[interface{}]interface{}
-	ExternalID map[interface{}]interface{}
+	UUID       string                      `ovsdb:"_uuid"`
+	CIDR       string                      `ovsdb:"cidr"`
+	Options    map[interface{}]interface{} `ovsdb:"options"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
 }
 
-func (odbi *ovnDBImp) RowToDHCPOptions(uuid string) *DHCPOptions {
-	dhcp := &DHCPOptions{
-		UUID:       uuid,
-		CIDR:       odbi.cache[tableDHCPOptions][uuid].Fields["cidr"].(string),
-		Options:    odbi.cache[tableDHCPOptions][uuid].Fields["options"].(libovsdb.OvsMap).GoMap,
-		ExternalID: odbi.cache[tableDHCPOptions][uuid].Fields["external_ids"].(libovsdb.OvsMap).GoMap,
+func (odbi *ovnDBImp) RowToDHCPOptions(uuid string) (*DHCPOptions, error) {
+	dhcp := &DHCPOptions{}
+	if err := decodeRow(uuid, odbi.cache[tableDHCPOptions][uuid].Fields, dhcp); err != nil {
+		return nil, err
 	}
-
-	return dhcp
+	return dhcp, nil
 }
 
-func newDHCPRow(cidr string, options map[string]string, external_ids map[string]string) (OVNRow, error) {
-	row := make(OVNRow)
-
+// newDHCPRow returns the row of the given values, the empty ones are left
+// out of it
+func (odbi *ovnDBImp) newDHCPRow(cidr string, options map[string]string, external_ids map[string]string) (OVNRow, error) {
+	var columns []string
 	if len(cidr) > 0 {
-		row["cidr"] = cidr
+		columns = append(columns, "cidr")
 	}
-
 	if options != nil {
-		oMap, err := libovsdb.NewOvsMap(options)
-		if err != nil {
-			return nil, err
-		}
-		row["options"] = oMap
+		columns = append(columns, "options")
 	}
-
 	if external_ids != nil {
-		oMap, err := libovsdb.NewOvsMap(external_ids)
-		if err != nil {
-			return nil, err
-		}
-		row["external_ids"] = oMap
+		columns = append(columns, "external_ids")
 	}
-
-	return row, nil
+	if len(columns) == 0 {
+		return make(OVNRow), nil
+	}
+	return odbi.encodeRow(tableDHCPOptions, &DHCPOptions{
+		CIDR:       cidr,
+		Options:    toModelMap(options),
+		ExternalID: toModelMap(external_ids),
+	}, columns...)
 }
 
 func (odbi *ovnDBImp) addDHCPOptionsImp(cidr string, options map[string]string, external_ids map[string]string) (*OvnCommand, error) {
@@ -70,7 +64,7 @@ func (odbi *ovnDBImp) addDHCPOptionsImp(cidr string, options map[string]string,
 		return nil, err
 	}
 
-	row, err := newDHCPRow(cidr, options, external_ids)
+	row, err := odbi.newDHCPRow(cidr, options, external_ids)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +82,7 @@ func (odbi *ovnDBImp) addDHCPOptionsImp(cidr string, options map[string]string,
 
 func (odbi *ovnDBImp) setDHCPOptionsImp(cidr string, options map[string]string, external_ids map[string]string) (*OvnCommand, error) {
 
-	row, err := newDHCPRow(cidr, nil, external_ids)
+	row, err := odbi.newDHCPRow(cidr, nil, external_ids)
 	if err != nil {
 		return nil, err
 	}
@@ -139,10 +133,12 @@ func (odbi *ovnDBImp) dhcpOptionsListImp() (*OvnCommand, error) {
 // Get all dhcp options
 func (odbi *ovnDBImp) getDHCPOptionsImp() []*DHCPOptions {
 	var dhcpList = []*DHCPOptions{}
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 	for uuid, _ := range odbi.cache[tableDHCPOptions] {
-		dhcpList = append(dhcpList, odbi.RowToDHCPOptions(uuid))
+		if dhcp, err := odbi.RowToDHCPOptions(uuid); err == nil {
+			dhcpList = append(dhcpList, dhcp)
+		}
 	}
 	return dhcpList
 }
@@ -55,6 +55,7 @@ func TestDHCPOptions(t *testing.T) {
 		},
 		nil)
 	cmds = append(cmds, cmd)
+	dhcpcmd := cmd
 
 	// execute to create lsw and lsp
 	err = ovndbapi.Execute(cmds...)
@@ -62,6 +63,11 @@ func TestDHCPOptions(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	dhcpUUID, err := dhcpcmd.UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	lsws := ovndbapi.GetLogicSwitches()
 	if len(lsws) != 1 {
 		t.Fatalf("ls not created %d", len(lsws))
@@ -71,6 +77,7 @@ func TestDHCPOptions(t *testing.T) {
 	if len(dhcp_opts) != 1 {
 		t.Fatalf("dhcp options not created %v", dhcp_opts)
 	}
+	assert.Equal(t, dhcp_opts[0].UUID, dhcpUUID, "test[%s]", "uuid returned by execute")
 
 	cmd, err = ovndbapi.LSPSetDHCPv4Options(LSP, dhcp_opts[0].UUID)
 	if err != nil {
@@ -133,3 +140,72 @@ func TestDHCPOptions(t *testing.T) {
 	}
 
 }
+
+func TestDHCPOptionsSameTransaction(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LSPAdd(LSW, LSP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.AddDHCPOptions("192.168.0.0/24", map[string]string{"lease_time": "6000"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	dhcpcmd := cmd
+
+	// bind the dhcp options created by the same transaction
+	namedUUID, err := dhcpcmd.NamedUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd, err = ovndbapi.LSPSetDHCPv4Options(LSP, namedUUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dhcpUUID, err := dhcpcmd.UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dhcp, err := ovndbapi.LSPGetDHCPv4Options(LSP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, dhcp.UUID == dhcpUUID && dhcp.CIDR == "192.168.0.0/24", "test[%s]: %v", "dhcpv4_options bound in one transaction", dhcp)
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LSWDel(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.DelDHCPOptions(dhcpUUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,224 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"github.com/unistack-org/libovsdb"
+)
+
+// DNS is a root table, a DNS row is kept by the database when no
+// Logical_Switch refers to it through dns_records anymore. LSWDetachDNS
+// deletes it when detaching it from its last switch.
+type DNS struct {
+	UUID       string                      `ovsdb:"_uuid"`
+	Records    map[interface{}]interface{} `ovsdb:"records"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+func newLSWDNSMutateOp(lsw string, dnsUUID string, mutator string) (libovsdb.Operation, error) {
+	mutateUUID := []libovsdb.UUID{{GoUUID: dnsUUID}}
+	mutateSet, err := libovsdb.NewOvsSet(mutateUUID)
+	if err != nil {
+		return libovsdb.Operation{}, err
+	}
+	mutation := libovsdb.NewMutation("dns_records", mutator, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", lsw)
+
+	return libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalSwitch,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}, nil
+}
+
+// dnsAddImp creates a DNS row with records and attaches it to lsw.
+func (odbi *ovnDBImp) dnsAddImp(lsw string, records map[string]string, external_ids map[string]string) (*OvnCommand, error) {
+	// the row would be left unattached
+	if err := odbi.checkLSWExists(lsw); err != nil {
+		return nil, err
+	}
+
+	namedUUID, err := newRowUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := odbi.encodeRow(tableDNS, &DNS{
+		Records:    toModelMap(records),
+		ExternalID: toModelMap(external_ids),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	insertOp := libovsdb.Operation{
+		Op:       opInsert,
+		Table:    tableDNS,
+		Row:      row,
+		UUIDName: namedUUID,
+	}
+
+	mutateOp, err := newLSWDNSMutateOp(lsw, namedUUID, opInsert)
+	if err != nil {
+		return nil, err
+	}
+	operations := []libovsdb.Operation{insertOp, mutateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+// checkLSWExists returns ErrorNotFound unless lsw is in the cache
+func (odbi *ovnDBImp) checkLSWExists(lsw string) error {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	_, _, err := odbi.getRowRefsByName(tableLogicalSwitch, lsw, "dns_records")
+	return err
+}
+
+// dnsDelImp detaches the DNS row from every lswitch and deletes it.
+func (odbi *ovnDBImp) dnsDelImp(uuid string) (*OvnCommand, error) {
+	var operations []libovsdb.Operation
+
+	odbi.cachemutex.RLock()
+	if _, ok := odbi.cache[tableDNS][uuid]; !ok {
+		odbi.cachemutex.RUnlock()
+		return nil, ErrorNotFound
+	}
+	lsws := odbi.getDNSSwitches(uuid)
+	odbi.cachemutex.RUnlock()
+
+	for _, lsw := range lsws {
+		mutateOp, err := newLSWDNSMutateOp(lsw, uuid, opDelete)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, mutateOp)
+	}
+
+	operations = append(operations, newDNSDeleteOp(uuid))
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+// getDNSSwitches returns the names of the lswitches referring to the DNS
+// row. Caller must hold cachemutex.
+func (odbi *ovnDBImp) getDNSSwitches(uuid string) []string {
+	var lsws []string
+	for _, drows := range odbi.cache[tableLogicalSwitch] {
+		for _, dns := range odbi.getRefUUIDs(drows.Fields["dns_records"]) {
+			if dns == uuid {
+				lsws = append(lsws, drows.Fields["name"].(string))
+				break
+			}
+		}
+	}
+	return lsws
+}
+
+func newDNSDeleteOp(uuid string) libovsdb.Operation {
+	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: uuid})
+	return libovsdb.Operation{
+		Op:    opDelete,
+		Table: tableDNS,
+		Where: []interface{}{condition},
+	}
+}
+
+func (odbi *ovnDBImp) dnsSetRecordsImp(uuid string, records map[string]string) (*OvnCommand, error) {
+	row := make(OVNRow)
+	oMap, err := libovsdb.NewOvsMap(records)
+	if err != nil {
+		return nil, err
+	}
+	row["records"] = oMap
+
+	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: uuid})
+	updateOp := libovsdb.Operation{
+		Op:    opUpdate,
+		Table: tableDNS,
+		Row:   row,
+		Where: []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{updateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) lswAttachDNSImp(lsw string, uuid string) (*OvnCommand, error) {
+	if err := odbi.checkLSWExists(lsw); err != nil {
+		return nil, err
+	}
+	mutateOp, err := newLSWDNSMutateOp(lsw, uuid, opInsert)
+	if err != nil {
+		return nil, err
+	}
+	operations := []libovsdb.Operation{mutateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+// lswDetachDNSImp removes the DNS row from lsw, and deletes it if lsw was
+// its last lswitch, as DNS is a root table.
+func (odbi *ovnDBImp) lswDetachDNSImp(lsw string, uuid string) (*OvnCommand, error) {
+	odbi.cachemutex.RLock()
+	lsws := odbi.getDNSSwitches(uuid)
+	odbi.cachemutex.RUnlock()
+
+	mutateOp, err := newLSWDNSMutateOp(lsw, uuid, opDelete)
+	if err != nil {
+		return nil, err
+	}
+	operations := []libovsdb.Operation{mutateOp}
+	if len(lsws) == 1 && lsws[0] == lsw {
+		operations = append(operations, newDNSDeleteOp(uuid))
+	}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) RowToDNS(uuid string) (*DNS, error) {
+	dns := &DNS{}
+	if err := decodeRow(uuid, odbi.cache[tableDNS][uuid].Fields, dns); err != nil {
+		return nil, err
+	}
+	return dns, nil
+}
+
+// Get dns by uuid
+func (odbi *ovnDBImp) GetDNS(uuid string) (*DNS, error) {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	if _, ok := odbi.cache[tableDNS][uuid]; !ok {
+		return nil, ErrorNotFound
+	}
+	return odbi.RowToDNS(uuid)
+}
+
+// Get all dns by lswitch
+func (odbi *ovnDBImp) GetDNSBySwitch(lsw string) ([]*DNS, error) {
+	var dnslist = []*DNS{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	_, dnsRecords, err := odbi.getRowRefsByName(tableLogicalSwitch, lsw, "dns_records")
+	if err != nil {
+		return nil, err
+	}
+	for _, dns := range dnsRecords {
+		if _, ok := odbi.cache[tableDNS][dns]; ok {
+			if d, err := odbi.RowToDNS(dns); err == nil {
+				dnslist = append(dnslist, d)
+			}
+		}
+	}
+	return dnslist, nil
+}
@@ -0,0 +1,153 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	LSW_SECOND = "TEST_LSW_SECOND"
+)
+
+func TestDNS(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LSWAdd(LSW_SECOND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.DNSAdd("missing", map[string]string{"vm1.example.org": "10.0.0.11"}, nil)
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "dns added to missing switch")
+
+	cmd, err = ovndbapi.DNSAdd(LSW, map[string]string{"vm1.example.org": "10.0.0.11"}, map[string]string{"A": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dnss, err := ovndbapi.GetDNSBySwitch(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dnss) != 1 {
+		t.Fatalf("dns not created %v", dnss)
+	}
+	assert.Equal(t, "10.0.0.11", dnss[0].Records["vm1.example.org"], "test[%s]", "dns records added")
+	dnsUUID := dnss[0].UUID
+
+	cmd, err = ovndbapi.DNSSetRecords(dnsUUID, map[string]string{"vm1.example.org": "10.0.0.11", "vm2.example.org": "10.0.0.12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dns, err := ovndbapi.GetDNS(dnsUUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(dns.Records) == 2 && dns.Records["vm2.example.org"] == "10.0.0.12", "test[%s]: %v", "dns records set", dns)
+
+	_, err = ovndbapi.LSWAttachDNS("missing", dnsUUID)
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "dns attached to missing switch")
+
+	cmd, err = ovndbapi.LSWAttachDNS(LSW_SECOND, dnsUUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dnss, err = ovndbapi.GetDNSBySwitch(LSW_SECOND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(dnss) == 1 && dnss[0].UUID == dnsUUID, "test[%s]: %v", "dns attached to second switch", dnss)
+
+	cmd, err = ovndbapi.LSWDetachDNS(LSW, dnsUUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dnss, err = ovndbapi.GetDNSBySwitch(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(dnss) == 0, "test[%s]: %v", "dns detached", dnss)
+	_, err = ovndbapi.GetDNS(dnsUUID)
+	assert.Nil(t, err, "test[%s]", "dns kept while attached to a switch")
+
+	cmd, err = ovndbapi.DNSDel(dnsUUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.GetDNS(dnsUUID)
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "dns removed")
+
+	// detached from its last switch the dns is deleted
+	cmd, err = ovndbapi.DNSAdd(LSW, map[string]string{"vm3.example.org": "10.0.0.13"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dnsUUID, err = cmd.UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd, err = ovndbapi.LSWDetachDNS(LSW, dnsUUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ovndbapi.GetDNS(dnsUUID)
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "dns deleted with its last switch")
+
+	for _, lsw := range []string{LSW, LSW_SECOND} {
+		cmd, err = ovndbapi.LSWDel(lsw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ovndbapi.Execute(cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
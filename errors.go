@@ -0,0 +1,110 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// Errors reported by ovsdb-server for a failed transaction, see RFC 7047
+// section 4.1.3. A *TransactionError matches the one of its code with
+// errors.Is.
+var (
+	ErrorReferentialIntegrity = errors.New("referential integrity violation")
+	ErrorConstraintViolation  = errors.New("constraint violation")
+	ErrorResourcesExhausted   = errors.New("resources exhausted")
+	ErrorIO                   = errors.New("I/O error")
+	ErrorDuplicateUUIDName    = errors.New("duplicate uuid-name")
+	ErrorDomain               = errors.New("domain error")
+	ErrorRange                = errors.New("range error")
+	ErrorTimeout              = errors.New("timed out")
+	ErrorNotSupported         = errors.New("not supported")
+	ErrorAborted              = errors.New("aborted")
+	ErrorNotOwner             = errors.New("not owner")
+	// ErrorIndexConflict is the constraint violation of two rows with the
+	// same value in an indexed column, such as two switches of one name
+	ErrorIndexConflict = errors.New("index conflict")
+)
+
+var transactionErrors = map[string]error{
+	ErrorReferentialIntegrity.Error(): ErrorReferentialIntegrity,
+	ErrorConstraintViolation.Error():  ErrorConstraintViolation,
+	ErrorResourcesExhausted.Error():   ErrorResourcesExhausted,
+	ErrorIO.Error():                   ErrorIO,
+	ErrorDuplicateUUIDName.Error():    ErrorDuplicateUUIDName,
+	ErrorDomain.Error():               ErrorDomain,
+	ErrorRange.Error():                ErrorRange,
+	ErrorTimeout.Error():              ErrorTimeout,
+	ErrorNotSupported.Error():         ErrorNotSupported,
+	ErrorAborted.Error():              ErrorAborted,
+	ErrorNotOwner.Error():             ErrorNotOwner,
+}
+
+// TransactionError is a transaction rejected by ovsdb-server
+type TransactionError struct {
+	// Code is the error of the result, e.g. "constraint violation"
+	Code    string
+	Details string
+	// Index is the index of the failed operation in the transaction, or
+	// -1 when every operation succeeded but the commit failed
+	Index int
+	// Operation is the failed operation, nil for a commit failure
+	Operation *libovsdb.Operation
+	// Command is the command of the failed operation, when it was issued
+	// by Execute
+	Command *OvnCommand
+}
+
+func newTransactionError(ops []libovsdb.Operation, index int, result libovsdb.OperationResult) *TransactionError {
+	e := &TransactionError{
+		Code:    result.Error,
+		Details: result.Details,
+		Index:   -1,
+	}
+	if index < len(ops) {
+		e.Index = index
+		e.Operation = &ops[index]
+	}
+	return e
+}
+
+func (e *TransactionError) Error() string {
+	if e.Operation == nil {
+		return fmt.Sprintf("transaction failed to commit: %s: %s", e.Code, e.Details)
+	}
+	return fmt.Sprintf("transaction failed at operation %d (%s %s): %s: %s", e.Index, e.Operation.Op, e.Operation.Table, e.Code, e.Details)
+}
+
+// Unwrap returns the sentinel error of the code, nil for unknown codes
+func (e *TransactionError) Unwrap() error {
+	return transactionErrors[e.Code]
+}
+
+// Is matches ErrorIndexConflict in addition to the sentinel of the code.
+// ovsdb-server reports an index conflict as a constraint violation, with no
+// code of its own, so it is told apart from the other constraint violations
+// by a heuristic: the "for index on" of its details.
+func (e *TransactionError) Is(target error) bool {
+	if target != ErrorIndexConflict || e.Code != ErrorConstraintViolation.Error() {
+		return false
+	}
+	return strings.Contains(e.Details, "for index on")
+}
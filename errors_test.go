@@ -0,0 +1,105 @@
+package goovn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+func TestTransactionError(t *testing.T) {
+	ops := []libovsdb.Operation{
+		{Op: opInsert, Table: tableLogicalSwitch},
+		{Op: opInsert, Table: tableLogicalSwitchPort},
+	}
+
+	txerr := newTransactionError(ops, 1, libovsdb.OperationResult{
+		Error:   "constraint violation",
+		Details: "Transaction causes multiple rows in \"Logical_Switch_Port\" table to have identical values (\"TEST_LSP\") for index on column \"name\".",
+	})
+	assert.Equal(t, ErrorConstraintViolation, txerr.Unwrap(), "test[%s]", "constraint violation")
+	assert.Equal(t, true, txerr.Is(ErrorIndexConflict), "test[%s]", "index conflict")
+	assert.Equal(t, false, txerr.Is(ErrorReferentialIntegrity), "test[%s]", "not a referential integrity error")
+	assert.Equal(t, 1, txerr.Index, "test[%s]", "failed operation index")
+	assert.Equal(t, tableLogicalSwitchPort, txerr.Operation.Table, "test[%s]", "failed operation")
+
+	txerr = newTransactionError(ops, 2, libovsdb.OperationResult{Error: "referential integrity violation"})
+	assert.Equal(t, ErrorReferentialIntegrity, txerr.Unwrap(), "test[%s]", "referential integrity violation")
+	assert.Equal(t, false, txerr.Is(ErrorIndexConflict), "test[%s]", "not an index conflict")
+	assert.Equal(t, true, txerr.Index == -1 && txerr.Operation == nil, "test[%s]", "commit failure")
+
+	txerr = newTransactionError(ops, 1, libovsdb.OperationResult{Error: "constraint violation", Details: "row is not referenced"})
+	assert.Equal(t, false, txerr.Is(ErrorIndexConflict), "test[%s]", "other constraint violation")
+	txerr = newTransactionError(ops, 1, libovsdb.OperationResult{Error: "domain error", Details: "for index on"})
+	assert.Equal(t, false, txerr.Is(ErrorIndexConflict), "test[%s]", "index details of another code")
+
+	txerr = newTransactionError(ops, 0, libovsdb.OperationResult{Error: "unknown error"})
+	assert.Nil(t, txerr.Unwrap(), "test[%s]", "unknown code")
+
+	timeout := &TimeoutError{Method: "transact", Err: context.DeadlineExceeded}
+	assert.Equal(t, true, timeout.Is(ErrorTimeout) && timeout.Unwrap() == context.DeadlineExceeded, "test[%s]", "deadline exceeded")
+	timeout = &TimeoutError{Method: "transact", Err: context.Canceled}
+	assert.Equal(t, false, timeout.Is(ErrorTimeout), "test[%s]", "canceled is no timeout")
+
+	first := &OvnCommand{Operations: ops[:1]}
+	second := &OvnCommand{Operations: ops[1:]}
+	assert.Equal(t, second, commandOfOperation([]*OvnCommand{first, nil, second}, 1), "test[%s]", "command of operation")
+	assert.Nil(t, commandOfOperation([]*OvnCommand{first, second}, 2), "test[%s]", "commit failure has no command")
+}
+
+func TestIndexConflict(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LSPAdd(LSW, LSP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LSPAdd(LSW, LSP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	err = ovndbapi.Execute(cmds...)
+	txerr, ok := err.(*TransactionError)
+	if assert.Equal(t, true, ok, "test[%s]: %v", "transaction error", err) {
+		assert.Equal(t, true, txerr.Is(ErrorIndexConflict), "test[%s]: %v", "same port added twice", err)
+		// ovsdb-server checks the indexes when the transaction commits
+		assert.Equal(t, -1, txerr.Index, "test[%s]", "failed operation index")
+		assert.Nil(t, txerr.Operation, "test[%s]", "failed operation")
+		assert.Nil(t, txerr.Command, "test[%s]", "failed command")
+	}
+
+	lsws := ovndbapi.GetLogicSwitches()
+	assert.Equal(t, 0, len(lsws), "test[%s]", "transaction rolled back")
+
+	first, err := ovndbapi.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ovndbapi.LSWAdd(LSW_SECOND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Operations[0].UUIDName = first.Operations[0].UUIDName
+	err = ovndbapi.Execute(first, second)
+	txerr, ok = err.(*TransactionError)
+	if assert.Equal(t, true, ok, "test[%s]: %v", "transaction error", err) {
+		assert.Equal(t, ErrorDuplicateUUIDName, txerr.Unwrap(), "test[%s]: %v", "uuid-name used twice", err)
+		assert.Equal(t, len(first.Operations), txerr.Index, "test[%s]", "failed operation index")
+		assert.Equal(t, &second.Operations[0], txerr.Operation, "test[%s]", "failed operation")
+		assert.Equal(t, second, txerr.Command, "test[%s]", "failed command")
+	}
+}
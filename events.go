@@ -0,0 +1,216 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"github.com/unistack-org/libovsdb"
+)
+
+// defaultEventBufferSize is the buffer of a subscription when
+// Config.EventBufferSize is not set
+const defaultEventBufferSize = 1024
+
+// EventType tells how a row changed
+type EventType int
+
+const (
+	EventCreate EventType = iota
+	EventUpdate
+	EventDelete
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventCreate:
+		return "create"
+	case EventUpdate:
+		return "update"
+	case EventDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// Event is a change of a cached row. Old is nil for EventCreate and New is
+// nil for EventDelete, both are otherwise given as to OVNTableSignal.
+type Event struct {
+	Type  EventType
+	Table string
+	UUID  string
+	Old   interface{}
+	New   interface{}
+}
+
+// EventFilter selects the events of a subscription. An empty filter selects
+// every event.
+type EventFilter struct {
+	// Tables are the tables of the events, all tables when empty
+	Tables []string
+	// ExternalIDs must all be in the external_ids of the row, either before
+	// or after the change, so a row leaving the filter is still seen
+	ExternalIDs map[string]string
+}
+
+func (f EventFilter) match(change tableChange) bool {
+	if len(f.Tables) > 0 {
+		found := false
+		for _, table := range f.Tables {
+			if table == change.event.Table {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.ExternalIDs) == 0 {
+		return true
+	}
+	return containsExternalIDs(change.oldIDs, f.ExternalIDs) ||
+		containsExternalIDs(change.newIDs, f.ExternalIDs)
+}
+
+func containsExternalIDs(ids map[interface{}]interface{}, want map[string]string) bool {
+	if ids == nil {
+		return false
+	}
+	for k, v := range want {
+		if id, ok := ids[k].(string); !ok || id != v {
+			return false
+		}
+	}
+	return true
+}
+
+// tableChange is an event with the external_ids of the row it was made of
+type tableChange struct {
+	event  Event
+	oldIDs map[interface{}]interface{}
+	newIDs map[interface{}]interface{}
+}
+
+func newTableChange(table, uuid string, oldRow, newRow *libovsdb.Row, oldObj, newObj interface{}) tableChange {
+	change := tableChange{event: Event{Type: EventUpdate, Table: table, UUID: uuid, Old: oldObj, New: newObj}}
+	switch {
+	case oldRow == nil:
+		change.event.Type = EventCreate
+	case newRow == nil:
+		change.event.Type = EventDelete
+	}
+	if oldRow != nil {
+		if ids, ok := oldRow.Fields["external_ids"].(libovsdb.OvsMap); ok {
+			change.oldIDs = ids.GoMap
+		}
+	}
+	if newRow != nil {
+		if ids, ok := newRow.Fields["external_ids"].(libovsdb.OvsMap); ok {
+			change.newIDs = ids.GoMap
+		}
+	}
+	return change
+}
+
+type subscription struct {
+	filter EventFilter
+	events chan Event
+}
+
+// Subscribe returns a channel receiving the events selected by filter, until
+// cancel is called or the client is closed, which close the channel. Events
+// are sent in the order the changes were received, once the cache holds
+// them. A subscriber not keeping up is cut off: when its buffer of
+// Config.EventBufferSize events is full, the channel is closed rather than
+// blocking the cache updates or silently dropping events. The cache is then
+// to be read again by a new subscription.
+func (odbi *ovnDBImp) Subscribe(filter EventFilter) (<-chan Event, func()) {
+	sub := &subscription{
+		filter: filter,
+		events: make(chan Event, odbi.eventBufferSize),
+	}
+
+	odbi.submutex.Lock()
+	defer odbi.submutex.Unlock()
+	if odbi.closed() {
+		close(sub.events)
+		return sub.events, func() {}
+	}
+	if odbi.subscriptions == nil {
+		odbi.subscriptions = make(map[*subscription]struct{})
+	}
+	odbi.subscriptions[sub] = struct{}{}
+	return sub.events, func() {
+		odbi.submutex.Lock()
+		defer odbi.submutex.Unlock()
+		odbi.unsubscribe(sub)
+	}
+}
+
+// unsubscribe closes the channel of sub once. Caller must hold submutex.
+func (odbi *ovnDBImp) unsubscribe(sub *subscription) {
+	if _, ok := odbi.subscriptions[sub]; ok {
+		delete(odbi.subscriptions, sub)
+		close(sub.events)
+	}
+}
+
+// subscribed tells if events are to be made for the subscriptions
+func (odbi *ovnDBImp) subscribed() bool {
+	odbi.submutex.Lock()
+	defer odbi.submutex.Unlock()
+	return len(odbi.subscriptions) > 0
+}
+
+// publish sends changes to the subscriptions selecting them, without ever
+// blocking.
+func (odbi *ovnDBImp) publish(changes []tableChange) {
+	if len(changes) == 0 {
+		return
+	}
+	odbi.submutex.Lock()
+	defer odbi.submutex.Unlock()
+	for sub := range odbi.subscriptions {
+		for _, change := range changes {
+			if !sub.filter.match(change) {
+				continue
+			}
+			if !sub.send(change.event) {
+				odbi.unsubscribe(sub)
+				break
+			}
+		}
+	}
+}
+
+// send queues event unless the buffer is full
+func (sub *subscription) send(event Event) bool {
+	select {
+	case sub.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// unsubscribeAll closes the channel of every subscription
+func (odbi *ovnDBImp) unsubscribeAll() {
+	odbi.submutex.Lock()
+	defer odbi.submutex.Unlock()
+	for sub := range odbi.subscriptions {
+		odbi.unsubscribe(sub)
+	}
+}
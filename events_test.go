@@ -0,0 +1,102 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+func lswRowWithIDs(name string, ids map[string]string) libovsdb.Row {
+	row := lswRow(name)
+	oMap, _ := libovsdb.NewOvsMap(ids)
+	row.Fields["external_ids"] = *oMap
+	return row
+}
+
+func receiveAll(events <-chan Event) []string {
+	var received []string
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return append(received, "closed")
+			}
+			received = append(received, event.Table+" "+event.UUID+" "+event.Type.String())
+		default:
+			return received
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	odbi := &ovnDBImp{
+		cache:           make(map[string]map[string]libovsdb.Row),
+		stop:            make(chan struct{}),
+		eventBufferSize: 8,
+	}
+
+	all, cancelAll := odbi.Subscribe(EventFilter{})
+	switches, _ := odbi.Subscribe(EventFilter{Tables: []string{tableLogicalSwitch}})
+	owned, _ := odbi.Subscribe(EventFilter{ExternalIDs: map[string]string{"owner": "me"}})
+
+	odbi.populateCache(lswUpdate("uuid-ls1", libovsdb.RowUpdate{New: lswRowWithIDs("ls1", map[string]string{"owner": "me"})}))
+	odbi.populateCache(lswUpdate("uuid-ls2", libovsdb.RowUpdate{New: lswRowWithIDs("ls2", map[string]string{"owner": "you"})}))
+	odbi.populateCache(libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableSSL: {Rows: map[string]libovsdb.RowUpdate{"uuid-ssl": {New: libovsdb.Row{Fields: map[string]interface{}{}}}}},
+	}})
+	// leaving the filter is still seen
+	odbi.populateCache(lswUpdate("uuid-ls1", libovsdb.RowUpdate{New: lswRowWithIDs("ls1", map[string]string{})}))
+	odbi.populateCache(lswUpdate("uuid-ls1", libovsdb.RowUpdate{Old: lswRow("ls1")}))
+
+	assert.Equal(t, []string{
+		"Logical_Switch uuid-ls1 create",
+		"Logical_Switch uuid-ls2 create",
+		"SSL uuid-ssl create",
+		"Logical_Switch uuid-ls1 update",
+		"Logical_Switch uuid-ls1 delete",
+	}, receiveAll(all), "test[%s]", "all events in order")
+	assert.Equal(t, []string{
+		"Logical_Switch uuid-ls1 create",
+		"Logical_Switch uuid-ls2 create",
+		"Logical_Switch uuid-ls1 update",
+		"Logical_Switch uuid-ls1 delete",
+	}, receiveAll(switches), "test[%s]", "table filter")
+	assert.Equal(t, []string{
+		"Logical_Switch uuid-ls1 create",
+		"Logical_Switch uuid-ls1 update",
+	}, receiveAll(owned), "test[%s]", "external_ids filter")
+
+	cancelAll()
+	cancelAll()
+	assert.Equal(t, []string{"closed"}, receiveAll(all), "test[%s]", "cancel closes the channel")
+
+	close(odbi.stop)
+	odbi.unsubscribeAll()
+	assert.Equal(t, []string{"closed"}, receiveAll(switches), "test[%s]", "close ends subscriptions")
+	late, _ := odbi.Subscribe(EventFilter{})
+	assert.Equal(t, []string{"closed"}, receiveAll(late), "test[%s]", "subscribe after close")
+}
+
+func TestSubscribeOverflow(t *testing.T) {
+	odbi := &ovnDBImp{
+		cache:           make(map[string]map[string]libovsdb.Row),
+		stop:            make(chan struct{}),
+		eventBufferSize: 2,
+	}
+
+	slow, _ := odbi.Subscribe(EventFilter{})
+	fast, _ := odbi.Subscribe(EventFilter{})
+	odbi.populateCache(lswUpdate("uuid-ls1", libovsdb.RowUpdate{New: lswRow("ls1")}))
+	odbi.populateCache(lswUpdate("uuid-ls2", libovsdb.RowUpdate{New: lswRow("ls2")}))
+	assert.Len(t, receiveAll(fast), 2, "test[%s]", "fast subscriber keeps up")
+	odbi.populateCache(lswUpdate("uuid-ls3", libovsdb.RowUpdate{New: lswRow("ls3")}))
+
+	assert.Equal(t, []string{
+		"Logical_Switch uuid-ls1 create",
+		"Logical_Switch uuid-ls2 create",
+		"closed",
+	}, receiveAll(slow), "test[%s]", "full subscription is closed")
+	assert.Equal(t, []string{"Logical_Switch uuid-ls3 create"}, receiveAll(fast), "test[%s]", "others not affected")
+	assert.Len(t, odbi.cache[tableLogicalSwitch], 3, "test[%s]", "cache updated")
+}
@@ -16,11 +16,245 @@
 
 package goovn
 
+import (
+	"fmt"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// Range of Gateway_Chassis priority
+const (
+	GatewayChassisMinPriority int = 0
+	GatewayChassisMaxPriority int = 32767
+)
+
 type GatewayChassis struct {
-	UUID        string
-	Name        string
-	ChassisName string
-	Priority    int
-	Options     map[interface{}]interface{}
-	ExternalID  map[interface{}]interface{}
+	UUID        string                      `ovsdb:"_uuid"`
+	Name        string                      `ovsdb:"name"`
+	ChassisName string                      `ovsdb:"chassis_name"`
+	Priority    int                         `ovsdb:"priority"`
+	Options     map[interface{}]interface{} `ovsdb:"options"`
+	ExternalID  map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+// gatewayChassisName follows the ovn-nbctl naming of gateway chassis rows.
+func gatewayChassisName(lrp, chassis string) string {
+	return lrp + "-" + chassis
+}
+
+// getGatewayChassisUUIDs returns the uuid of lrp and the uuids of its gateway
+// chassis keyed by chassis name.
+func (odbi *ovnDBImp) getGatewayChassisUUIDs(lrp string) (string, map[string]string, error) {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	lrpUUID, gcs, err := odbi.getRowRefsByName(tableLogicalRouterPort, lrp, "gateway_chassis")
+	if err != nil {
+		return "", nil, err
+	}
+	gcUUIDs := make(map[string]string, len(gcs))
+	for _, gc := range gcs {
+		if chassis, ok := odbi.cache[tableGatewayChassis][gc].Fields["chassis_name"].(string); ok {
+			gcUUIDs[chassis] = gc
+		}
+	}
+	return lrpUUID, gcUUIDs, nil
+}
+
+func (odbi *ovnDBImp) gatewayChassisInsertOps(lrpUUID, lrp, chassis string, priority int) ([]libovsdb.Operation, error) {
+	namedUUID, err := newRowUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := odbi.encodeRow(tableGatewayChassis, &GatewayChassis{
+		Name:        gatewayChassisName(lrp, chassis),
+		ChassisName: chassis,
+		Priority:    priority,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	insertOp := libovsdb.Operation{
+		Op:       opInsert,
+		Table:    tableGatewayChassis,
+		Row:      row,
+		UUIDName: namedUUID,
+	}
+
+	mutateUUID := []libovsdb.UUID{{GoUUID: namedUUID}}
+	mutateSet, err := libovsdb.NewOvsSet(mutateUUID)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("gateway_chassis", opInsert, mutateSet)
+	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: lrpUUID})
+
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalRouterPort,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+	return []libovsdb.Operation{insertOp, mutateOp}, nil
+}
+
+func gatewayChassisUpdateOp(gcUUID string, priority int) libovsdb.Operation {
+	row := make(OVNRow)
+	row["priority"] = priority
+	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: gcUUID})
+	return libovsdb.Operation{
+		Op:    opUpdate,
+		Table: tableGatewayChassis,
+		Row:   row,
+		Where: []interface{}{condition},
+	}
+}
+
+func gatewayChassisDeleteOps(lrpUUID string, gcUUIDs []libovsdb.UUID) ([]libovsdb.Operation, error) {
+	mutateSet, err := libovsdb.NewOvsSet(gcUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("gateway_chassis", opDelete, mutateSet)
+	mucondition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: lrpUUID})
+
+	// simple mutate operation
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalRouterPort,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{mucondition},
+	}
+	operations := []libovsdb.Operation{mutateOp}
+
+	for _, gcUUID := range gcUUIDs {
+		condition := libovsdb.NewCondition("_uuid", "==", gcUUID)
+		deleteOp := libovsdb.Operation{
+			Op:    opDelete,
+			Table: tableGatewayChassis,
+			Where: []interface{}{condition},
+		}
+		operations = append(operations, deleteOp)
+	}
+	return operations, nil
+}
+
+// lrpSetGatewayChassisImp schedules lrp on chassis with priority, the
+// priority is updated if lrp is already scheduled on chassis.
+func (odbi *ovnDBImp) lrpSetGatewayChassisImp(lrp, chassis string, priority int) (*OvnCommand, error) {
+	if priority < GatewayChassisMinPriority || priority > GatewayChassisMaxPriority {
+		return nil, fmt.Errorf("gateway chassis priority %d out of range [%d, %d]", priority, GatewayChassisMinPriority, GatewayChassisMaxPriority)
+	}
+
+	lrpUUID, gcUUIDs, err := odbi.getGatewayChassisUUIDs(lrp)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []libovsdb.Operation
+	if gcUUID, ok := gcUUIDs[chassis]; ok {
+		operations = []libovsdb.Operation{gatewayChassisUpdateOp(gcUUID, priority)}
+	} else {
+		operations, err = odbi.gatewayChassisInsertOps(lrpUUID, lrp, chassis, priority)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) lrpDelGatewayChassisImp(lrp, chassis string) (*OvnCommand, error) {
+	lrpUUID, gcUUIDs, err := odbi.getGatewayChassisUUIDs(lrp)
+	if err != nil {
+		return nil, err
+	}
+
+	gcUUID, ok := gcUUIDs[chassis]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+
+	operations, err := gatewayChassisDeleteOps(lrpUUID, []libovsdb.UUID{{GoUUID: gcUUID}})
+	if err != nil {
+		return nil, err
+	}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+// lrpRebalanceGatewayChassisImp schedules lrp on exactly the given chassis.
+// chassis is ordered by preference, the first one becomes the active gateway
+// and the following ones its standbys in failover order. Gateway chassis of
+// lrp missing from the list are removed. A chassis given twice is an error.
+func (odbi *ovnDBImp) lrpRebalanceGatewayChassisImp(lrp string, chassis []string) (*OvnCommand, error) {
+	if len(chassis) > GatewayChassisMaxPriority+1 {
+		return nil, fmt.Errorf("too many gateway chassis %d", len(chassis))
+	}
+	seen := make(map[string]bool, len(chassis))
+	for _, ch := range chassis {
+		if seen[ch] {
+			return nil, fmt.Errorf("gateway chassis %s given twice", ch)
+		}
+		seen[ch] = true
+	}
+
+	lrpUUID, gcUUIDs, err := odbi.getGatewayChassisUUIDs(lrp)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []libovsdb.Operation
+	for i, ch := range chassis {
+		priority := GatewayChassisMaxPriority - i
+		if gcUUID, ok := gcUUIDs[ch]; ok {
+			operations = append(operations, gatewayChassisUpdateOp(gcUUID, priority))
+			delete(gcUUIDs, ch)
+		} else {
+			ops, err := odbi.gatewayChassisInsertOps(lrpUUID, lrp, ch, priority)
+			if err != nil {
+				return nil, err
+			}
+			operations = append(operations, ops...)
+		}
+	}
+
+	if len(gcUUIDs) > 0 {
+		var stale []libovsdb.UUID
+		for _, gcUUID := range gcUUIDs {
+			stale = append(stale, libovsdb.UUID{GoUUID: gcUUID})
+		}
+		ops, err := gatewayChassisDeleteOps(lrpUUID, stale)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, ops...)
+	}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) RowToGatewayChassis(uuid string) (*GatewayChassis, error) {
+	gwc := &GatewayChassis{}
+	if err := decodeRow(uuid, odbi.cache[tableGatewayChassis][uuid].Fields, gwc); err != nil {
+		return nil, err
+	}
+	return gwc, nil
+}
+
+// Get all gateway chassis by lrp
+func (odbi *ovnDBImp) GetGatewayChassis(lrp string) ([]*GatewayChassis, error) {
+	var gclist = []*GatewayChassis{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	_, gcs, err := odbi.getRowRefsByName(tableLogicalRouterPort, lrp, "gateway_chassis")
+	if err != nil {
+		return nil, err
+	}
+	for _, gc := range gcs {
+		if _, ok := odbi.cache[tableGatewayChassis][gc]; ok {
+			if gwc, err := odbi.RowToGatewayChassis(gc); err == nil {
+				gclist = append(gclist, gwc)
+			}
+		}
+	}
+	return gclist, nil
 }
@@ -0,0 +1,126 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gatewayChassisPriorities(t *testing.T, lrp string) map[string]int {
+	gcs, err := ovndbapi.GetGatewayChassis(lrp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priorities := make(map[string]int)
+	for _, gc := range gcs {
+		priorities[gc.ChassisName] = gc.Priority
+	}
+	return priorities
+}
+
+func TestGatewayChassis(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LRAdd(LR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LRPAdd(LR, LRP, "54:54:54:54:54:54", []string{"192.168.0.1/24"}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.LRPSetGatewayChassis(LRP, "chassis1", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.LRPSetGatewayChassis(LRP, "chassis2", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.LRPSetGatewayChassis(LRP, "chassis3", 32768)
+	assert.Equal(t, true, err != nil, "test[%s]", "priority out of range rejected")
+
+	priorities := gatewayChassisPriorities(t, LRP)
+	assert.Equal(t, map[string]int{"chassis1": 20, "chassis2": 10}, priorities, "test[%s]", "gateway chassis added")
+
+	lrps, err := ovndbapi.GetLogicalRouterPortsByRouter(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(lrps) == 1 && len(lrps[0].GatewayChassis) == 2, "test[%s]: %v", "lrp gateway_chassis column", lrps)
+
+	// existing chassis only gets its priority updated
+	cmd, err = ovndbapi.LRPSetGatewayChassis(LRP, "chassis2", 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priorities = gatewayChassisPriorities(t, LRP)
+	assert.Equal(t, map[string]int{"chassis1": 20, "chassis2": 30}, priorities, "test[%s]", "gateway chassis priority updated")
+
+	cmd, err = ovndbapi.LRPRebalanceGatewayChassis(LRP, []string{"chassis3", "chassis1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priorities = gatewayChassisPriorities(t, LRP)
+	assert.Equal(t, map[string]int{"chassis3": 32767, "chassis1": 32766}, priorities, "test[%s]", "gateway chassis rebalanced")
+
+	for _, chassis := range [][]string{{"chassis1", "chassis1"}, {"chassis2", "chassis4", "chassis2"}} {
+		_, err = ovndbapi.LRPRebalanceGatewayChassis(LRP, chassis)
+		assert.NotNil(t, err, "test[%s]", "rebalance on duplicate chassis")
+	}
+
+	cmd, err = ovndbapi.LRPDelGatewayChassis(LRP, "chassis3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priorities = gatewayChassisPriorities(t, LRP)
+	assert.Equal(t, map[string]int{"chassis1": 32766}, priorities, "test[%s]", "gateway chassis removed")
+
+	_, err = ovndbapi.LRPDelGatewayChassis(LRP, "chassis3")
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "remove missing gateway chassis")
+
+	cmd, err = ovndbapi.LRDel(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
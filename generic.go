@@ -0,0 +1,213 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"fmt"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// Condition is a condition on a column for Find. Function is one of "==",
+// "!=", "includes", "excludes", "<", "<=", ">" and ">=", Value is converted
+// to the type of the column as for Set.
+type Condition struct {
+	Column   string
+	Function string
+	Value    interface{}
+}
+
+// getRecordUUID returns the uuid of record in table, record being the uuid
+// or the name of the row as for ovn-nbctl
+func (odbi *ovnDBImp) getRecordUUID(table, record string) (string, error) {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	return odbi.findRecord(table, record)
+}
+
+// findRecord is getRecordUUID for callers holding cachemutex
+func (odbi *ovnDBImp) findRecord(table, record string) (string, error) {
+	if _, ok := odbi.cache[table][record]; ok {
+		return record, nil
+	}
+	var found []string
+	for uuid, drows := range odbi.cache[table] {
+		if name, ok := drows.Fields["name"].(string); ok && name == record {
+			found = append(found, uuid)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", ErrorNotFound
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("multiple rows in %s are named %s", table, record)
+}
+
+func (odbi *ovnDBImp) listImp(table string) (*OvnCommand, error) {
+	return odbi.findImp(table)
+}
+
+func (odbi *ovnDBImp) findImp(table string, conditions ...Condition) (*OvnCommand, error) {
+	// fails on an unknown table
+	if _, err := odbi.getColumnType(table, "_uuid"); err != nil {
+		return nil, err
+	}
+	where := []interface{}{}
+	for _, c := range conditions {
+		t, err := odbi.getColumnType(table, c.Column)
+		if err != nil {
+			return nil, err
+		}
+		value, err := t.toOvsdb(c.Value, true)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", c.Column, err)
+		}
+		where = append(where, libovsdb.NewCondition(c.Column, c.Function, value))
+	}
+	selectOp := libovsdb.Operation{
+		Op:    opSelect,
+		Table: table,
+		Where: where,
+	}
+	operations := []libovsdb.Operation{selectOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) getImp(table, record, column string) (*OvnCommand, error) {
+	if _, err := odbi.getColumnType(table, column); err != nil {
+		return nil, err
+	}
+	uuid, err := odbi.getRecordUUID(table, record)
+	if err != nil {
+		return nil, err
+	}
+	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{uuid})
+	selectOp := libovsdb.Operation{
+		Op:      opSelect,
+		Table:   table,
+		Where:   []interface{}{condition},
+		Columns: []string{column},
+	}
+	operations := []libovsdb.Operation{selectOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) setImp(table, record string, values map[string]interface{}) (*OvnCommand, error) {
+	row := make(OVNRow)
+	for column, v := range values {
+		t, err := odbi.getColumnType(table, column)
+		if err != nil {
+			return nil, err
+		}
+		row[column], err = t.toOvsdb(v, false)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", column, err)
+		}
+	}
+	return odbi.updateRecord(table, record, row)
+}
+
+func (odbi *ovnDBImp) clearImp(table, record string, columns ...string) (*OvnCommand, error) {
+	row := make(OVNRow)
+	for _, column := range columns {
+		t, err := odbi.getColumnType(table, column)
+		if err != nil {
+			return nil, err
+		}
+		if t.min > 0 {
+			return nil, fmt.Errorf("column %s of table %s cannot be empty", column, table)
+		}
+		if t.isMap() {
+			row[column] = emptyMap()
+		} else {
+			row[column] = libovsdb.OvsSet{GoSet: []interface{}{}}
+		}
+	}
+	return odbi.updateRecord(table, record, row)
+}
+
+func (odbi *ovnDBImp) updateRecord(table, record string, row OVNRow) (*OvnCommand, error) {
+	uuid, err := odbi.getRecordUUID(table, record)
+	if err != nil {
+		return nil, err
+	}
+	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{uuid})
+	updateOp := libovsdb.Operation{
+		Op:    opUpdate,
+		Table: table,
+		Row:   row,
+		Where: []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{updateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) addImp(table, record, column string, values interface{}) (*OvnCommand, error) {
+	t, err := odbi.getColumnType(table, column)
+	if err != nil {
+		return nil, err
+	}
+	if t.max == 1 && !t.isMap() {
+		return nil, fmt.Errorf("column %s of table %s is not a set nor a map", column, table)
+	}
+	arg, err := t.toOvsdb(values, true)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %v", column, err)
+	}
+	return odbi.mutateRecord(table, record, column, opInsert, arg)
+}
+
+func (odbi *ovnDBImp) removeImp(table, record, column string, values interface{}) (*OvnCommand, error) {
+	t, err := odbi.getColumnType(table, column)
+	if err != nil {
+		return nil, err
+	}
+	if t.max == 1 && !t.isMap() {
+		return nil, fmt.Errorf("column %s of table %s is not a set nor a map", column, table)
+	}
+	var arg interface{}
+	if _, ok := values.(libovsdb.OvsMap); t.isMap() && !ok && !isGoMap(values) {
+		// a map loses the pairs of the given keys
+		keys := &columnType{key: t.key, min: 0, max: -1}
+		arg, err = keys.toOvsdb(values, true)
+	} else {
+		arg, err = t.toOvsdb(values, true)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %v", column, err)
+	}
+	return odbi.mutateRecord(table, record, column, opDelete, arg)
+}
+
+func (odbi *ovnDBImp) mutateRecord(table, record, column, mutator string, arg interface{}) (*OvnCommand, error) {
+	uuid, err := odbi.getRecordUUID(table, record)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation(column, mutator, arg)
+	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{uuid})
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     table,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{mutateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
@@ -0,0 +1,72 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+const (
+	LSW_GENERIC = "TEST_LSW_GENERIC"
+)
+
+func TestGeneric(t *testing.T) {
+	executeGeneric := func(cmd *OvnCommand, err error) *OvnCommand {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ovndbapi.Execute(cmd); err != nil {
+			t.Fatal(err)
+		}
+		return cmd
+	}
+	executeGeneric(ovndbapi.LSWAdd(LSW_GENERIC))
+	defer func() {
+		executeGeneric(ovndbapi.LSWDel(LSW_GENERIC))
+	}()
+
+	executeGeneric(ovndbapi.Set(tableLogicalSwitch, LSW_GENERIC, map[string]interface{}{
+		"other_config": map[string]string{"subnet": "10.0.0.0/24"},
+	}))
+	executeGeneric(ovndbapi.Add(tableLogicalSwitch, LSW_GENERIC, "other_config", map[string]string{"exclude_ips": "10.0.0.1"}))
+	executeGeneric(ovndbapi.Remove(tableLogicalSwitch, LSW_GENERIC, "other_config", "subnet"))
+	cmd := executeGeneric(ovndbapi.Get(tableLogicalSwitch, LSW_GENERIC, "other_config"))
+	assert.Equal(t, libovsdb.OvsMap{GoMap: map[interface{}]interface{}{"exclude_ips": "10.0.0.1"}},
+		cmd.Results[0][0]["other_config"], "test[%s]", "set, add and remove map values")
+
+	cmd = executeGeneric(ovndbapi.Find(tableLogicalSwitch,
+		Condition{"name", "==", LSW_GENERIC},
+		Condition{"other_config", "includes", map[string]string{"exclude_ips": "10.0.0.1"}}))
+	assert.Len(t, cmd.Results[0], 1, "test[%s]", "find")
+	uuid := cmd.Results[0][0]["_uuid"].(libovsdb.UUID).GoUUID
+
+	cmd = executeGeneric(ovndbapi.List(tableLogicalSwitch))
+	found := false
+	for _, row := range cmd.Results[0] {
+		found = found || row["name"] == LSW_GENERIC
+	}
+	assert.True(t, found, "test[%s]", "list")
+
+	cmd = executeGeneric(ovndbapi.Get(tableLogicalSwitch, uuid, "name"))
+	assert.Equal(t, LSW_GENERIC, cmd.Results[0][0]["name"], "test[%s]", "record by uuid")
+
+	executeGeneric(ovndbapi.Clear(tableLogicalSwitch, LSW_GENERIC, "other_config"))
+	cmd = executeGeneric(ovndbapi.Get(tableLogicalSwitch, LSW_GENERIC, "other_config"))
+	assert.Len(t, cmd.Results[0][0]["other_config"].(libovsdb.OvsMap).GoMap, 0, "test[%s]", "clear")
+
+	_, err := ovndbapi.Get(tableLogicalSwitch, "TEST_LSW_MISSING", "name")
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "unknown record")
+	_, err = ovndbapi.Get(tableLogicalSwitch, LSW_GENERIC, "missing")
+	assert.NotNil(t, err, "test[%s]", "unknown column")
+	_, err = ovndbapi.List("Missing")
+	assert.NotNil(t, err, "test[%s]", "unknown table")
+	_, err = ovndbapi.Set(tableLogicalSwitch, LSW_GENERIC, map[string]interface{}{"name": 1})
+	assert.NotNil(t, err, "test[%s]", "wrong type")
+	_, err = ovndbapi.Set(tableLogicalSwitch, LSW_GENERIC, map[string]interface{}{"name": []string{}})
+	assert.NotNil(t, err, "test[%s]", "too few values")
+	_, err = ovndbapi.Clear(tableLogicalSwitch, LSW_GENERIC, "name")
+	assert.NotNil(t, err, "test[%s]", "clear a required column")
+	_, err = ovndbapi.Add(tableLogicalSwitch, LSW_GENERIC, "name", "ls")
+	assert.NotNil(t, err, "test[%s]", "add to a scalar column")
+}
@@ -4,7 +4,7 @@ go 1.12
 
 require (
 	github.com/cenkalti/hub v1.0.1-0.20160527103212-11382a9960d3 // indirect
-	github.com/cenkalti/rpc2 v0.0.0-20170726070524-c51a77e5f664 // indirect
+	github.com/cenkalti/rpc2 v0.0.0-20170726070524-c51a77e5f664
 	github.com/google/uuid v1.1.1
 	github.com/stretchr/testify v1.3.0
 )
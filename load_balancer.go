@@ -23,11 +23,11 @@ import (
 )
 
 type LoadBalancer struct {
-	UUID       string
-	Name       string
+	UUID       string `ovsdb:"_uuid"`
+	Name       string `ovsdb:"name"`
 	vips       map[interface{}]interface{}
 	protocol   string
-	ExternalID map[interface{}]interface{}
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
 }
 
 func (odbi *ovnDBImp) lbUpdateImp(name string, vipPort string, protocol string, addrs []string) (*OvnCommand, error) {
@@ -124,24 +124,33 @@ func (odbi *ovnDBImp) lbDelImp(name string) (*OvnCommand, error) {
 
 func (odbi *ovnDBImp) GetLB(name string) []*LoadBalancer {
 	var lbList []*LoadBalancer
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 
 	for uuid, drows := range odbi.cache[tableLoadBalancer] {
 		if lbName, ok := drows.Fields["name"].(string); ok && lbName == name {
-			lb := odbi.RowToLB(uuid)
-			lbList = append(lbList, lb)
+			if lb, err := odbi.RowToLB(uuid); err == nil {
+				lbList = append(lbList, lb)
+			}
 		}
 	}
 	return lbList
 }
 
-func (odbi *ovnDBImp) RowToLB(uuid string) *LoadBalancer {
-	return &LoadBalancer{
-		UUID:       uuid,
-		protocol:   odbi.cache[tableLoadBalancer][uuid].Fields["protocol"].(string),
-		Name:       odbi.cache[tableLoadBalancer][uuid].Fields["name"].(string),
-		vips:       odbi.cache[tableLoadBalancer][uuid].Fields["vips"].(libovsdb.OvsMap).GoMap,
-		ExternalID: odbi.cache[tableLoadBalancer][uuid].Fields["external_ids"].(libovsdb.OvsMap).GoMap,
+func (odbi *ovnDBImp) RowToLB(uuid string) (*LoadBalancer, error) {
+	lb := &LoadBalancer{}
+	if err := decodeRow(uuid, odbi.cache[tableLoadBalancer][uuid].Fields, lb); err != nil {
+		return nil, err
+	}
+
+	// unexported fields are not decoded
+	if vips, ok := odbi.cache[tableLoadBalancer][uuid].Fields["vips"].(libovsdb.OvsMap); ok {
+		lb.vips = vips.GoMap
 	}
+	// protocol is optional, an empty set when not given
+	if protocol, ok := odbi.cache[tableLoadBalancer][uuid].Fields["protocol"].(string); ok {
+		lb.protocol = protocol
+	}
+
+	return lb, nil
 }
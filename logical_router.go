@@ -21,17 +21,17 @@ import (
 )
 
 type LogicalRouter struct {
-	UUID    string
-	Name    string
-	Enabled bool
+	UUID    string `ovsdb:"_uuid"`
+	Name    string `ovsdb:"name"`
+	Enabled bool   `ovsdb:"enabled"`
 
-	Ports        []string
-	StaticRoutes []string
-	NAT          []string
-	LoadBalancer []string
+	Ports        []string `ovsdb:"ports"`
+	StaticRoutes []string `ovsdb:"static_routes"`
+	NAT          []string `ovsdb:"nat"`
+	LoadBalancer []string `ovsdb:"load_balancer"`
 
-	Options    map[interface{}]interface{}
-	ExternalID map[interface{}]interface{}
+	Options    map[interface{}]interface{} `ovsdb:"options"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
 }
 
 func (odbi *ovnDBImp) lrAddImp(name string, external_ids map[string]string) (*OvnCommand, error) {
@@ -79,55 +79,42 @@ func (odbi *ovnDBImp) lrDelImp(name string) (*OvnCommand, error) {
 
 func (odbi *ovnDBImp) GetLogicalRouter(name string) []*LogicalRouter {
 	var lrList []*LogicalRouter
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 
 	for uuid, drows := range odbi.cache[tableLogicalRouter] {
 		if lrName, ok := drows.Fields["name"].(string); ok && lrName == name {
-			lr := odbi.RowToLogicalRouter(uuid)
-			lrList = append(lrList, lr)
+			if lr, err := odbi.RowToLogicalRouter(uuid); err == nil {
+				lrList = append(lrList, lr)
+			}
 		}
 	}
 	return lrList
 }
 
-func (odbi *ovnDBImp) RowToLogicalRouter(uuid string) *LogicalRouter {
-	lr := &LogicalRouter{
-		UUID:       uuid,
-		Name:       odbi.cache[tableLogicalRouter][uuid].Fields["name"].(string),
-		Options:    odbi.cache[tableLogicalRouter][uuid].Fields["options"].(libovsdb.OvsMap).GoMap,
-		ExternalID: odbi.cache[tableLogicalRouter][uuid].Fields["external_ids"].(libovsdb.OvsMap).GoMap,
-	}
-
-	if enabled, ok := odbi.cache[tableLogicalRouter][uuid].Fields["enabled"]; ok {
-		switch enabled.(type) {
-		case bool:
-			lr.Enabled = enabled.(bool)
-		case libovsdb.OvsSet:
-			if enabled.(libovsdb.OvsSet).GoSet == nil {
-				lr.Enabled = true
-			}
-		}
+func (odbi *ovnDBImp) RowToLogicalRouter(uuid string) (*LogicalRouter, error) {
+	lr := &LogicalRouter{}
+	if err := decodeRow(uuid, odbi.cache[tableLogicalRouter][uuid].Fields, lr); err != nil {
+		return nil, err
 	}
 
-	ports := odbi.cache[tableLogicalRouter][uuid].Fields["ports"]
-	switch ports.(type) {
-	case string:
-		lr.Ports = []string{ports.(string)}
-	case libovsdb.OvsSet:
-		lr.Ports = odbi.ConvertGoSetToStringArray(ports.(libovsdb.OvsSet))
+	// a router is enabled unless enabled is false
+	if enabled, ok := odbi.cache[tableLogicalRouter][uuid].Fields["enabled"].(libovsdb.OvsSet); ok && len(enabled.GoSet) == 0 {
+		lr.Enabled = true
 	}
 
-	return lr
+	return lr, nil
 }
 
 // Get all logical switches
 func (odbi *ovnDBImp) GetLogicalRouters() []*LogicalRouter {
 	var lrlist = []*LogicalRouter{}
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 	for uuid, _ := range odbi.cache[tableLogicalRouter] {
-		lrlist = append(lrlist, odbi.RowToLogicalRouter(uuid))
+		if lr, err := odbi.RowToLogicalRouter(uuid); err == nil {
+			lrlist = append(lrlist, lr)
+		}
 	}
 	return lrlist
 }
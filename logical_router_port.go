@@ -23,16 +23,16 @@ import (
 )
 
 type LogicalRouterPort struct {
-	UUID           string
-	Name           string
-	GatewayChassis []string
-	Networks       []string
-	MAC            string
-	Enabled        bool
-	IPv6RAConfigs  map[interface{}]interface{}
-	Options        map[interface{}]interface{}
-	Peer           string
-	ExternalID     map[interface{}]interface{}
+	UUID           string                      `ovsdb:"_uuid"`
+	Name           string                      `ovsdb:"name"`
+	GatewayChassis []string                    `ovsdb:"gateway_chassis"`
+	Networks       []string                    `ovsdb:"networks"`
+	MAC            string                      `ovsdb:"mac"`
+	Enabled        bool                        `ovsdb:"enabled"`
+	IPv6RAConfigs  map[interface{}]interface{} `ovsdb:"ipv6_ra_configs"`
+	Options        map[interface{}]interface{} `ovsdb:"options"`
+	Peer           string                      `ovsdb:"peer"`
+	ExternalID     map[interface{}]interface{} `ovsdb:"external_ids"`
 }
 
 func (odbi *ovnDBImp) lrpAddImp(lr string, lrp string, mac string, network []string, peer string, external_ids map[string]string) (*OvnCommand, error) {
@@ -65,6 +65,17 @@ func (odbi *ovnDBImp) lrpAddImp(lr string, lrp string, mac string, network []str
 		return nil, ErrorExist
 	}
 
+	row, err = odbi.encodeRow(tableLogicalRouterPort, &LogicalRouterPort{
+		Name:       lrp,
+		Networks:   network,
+		MAC:        mac,
+		Peer:       peer,
+		ExternalID: toModelMap(external_ids),
+	}, "name", "networks", "mac", "peer", "external_ids")
+	if err != nil {
+		return nil, err
+	}
+
 	insertOp := libovsdb.Operation{
 		Op:       opInsert,
 		Table:    tableLogicalRouterPort,
@@ -129,62 +140,24 @@ func (odbi *ovnDBImp) lrpDelImp(lr, lrp string) (*OvnCommand, error) {
 	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
 }
 
-func (odbi *ovnDBImp) RowToLogicalRouterPort(uuid string) *LogicalRouterPort {
-	lrp := &LogicalRouterPort{
-		UUID:       uuid,
-		Name:       odbi.cache[tableLogicalRouterPort][uuid].Fields["name"].(string),
-		MAC:        odbi.cache[tableLogicalRouterPort][uuid].Fields["mac"].(string),
-		ExternalID: odbi.cache[tableLogicalRouterPort][uuid].Fields["external_ids"].(libovsdb.OvsMap).GoMap,
-	}
-
-	if peer, ok := odbi.cache[tableLogicalRouterPort][uuid].Fields["peer"]; ok {
-		switch peer.(type) {
-		case string:
-			lrp.Peer = peer.(string)
-		}
-	}
-
-	if options, ok := odbi.cache[tableLogicalRouterPort][uuid].Fields["options"]; ok {
-		lrp.Options = options.(libovsdb.OvsMap).GoMap
-	}
-
-	if ipv6_ra_configs, ok := odbi.cache[tableLogicalRouterPort][uuid].Fields["ipv6_ra_configs"]; ok {
-		lrp.IPv6RAConfigs = ipv6_ra_configs.(libovsdb.OvsMap).GoMap
-	}
-
-	if enabled, ok := odbi.cache[tableLogicalRouterPort][uuid].Fields["enabled"]; ok {
-		switch enabled.(type) {
-		case bool:
-			lrp.Enabled = enabled.(bool)
-		case libovsdb.OvsSet:
-			if enabled.(libovsdb.OvsSet).GoSet == nil {
-				lrp.Enabled = true
-			}
-		}
+func (odbi *ovnDBImp) RowToLogicalRouterPort(uuid string) (*LogicalRouterPort, error) {
+	lrp := &LogicalRouterPort{}
+	if err := decodeRow(uuid, odbi.cache[tableLogicalRouterPort][uuid].Fields, lrp); err != nil {
+		return nil, err
 	}
 
-	gateway_chassis := odbi.cache[tableLogicalRouterPort][uuid].Fields["gateway_chassis"]
-	switch gateway_chassis.(type) {
-	case string:
-		lrp.GatewayChassis = []string{gateway_chassis.(string)}
-	case libovsdb.OvsSet:
-		lrp.GatewayChassis = odbi.ConvertGoSetToStringArray(gateway_chassis.(libovsdb.OvsSet))
-	}
-	networks := odbi.cache[tableLogicalRouterPort][uuid].Fields["networks"]
-	switch networks.(type) {
-	case string:
-		lrp.Networks = []string{networks.(string)}
-	case libovsdb.OvsSet:
-		lrp.Networks = odbi.ConvertGoSetToStringArray(networks.(libovsdb.OvsSet))
+	// a port is enabled unless enabled is false
+	if enabled, ok := odbi.cache[tableLogicalRouterPort][uuid].Fields["enabled"].(libovsdb.OvsSet); ok && len(enabled.GoSet) == 0 {
+		lrp.Enabled = true
 	}
 
-	return lrp
+	return lrp, nil
 }
 
 func (odbi *ovnDBImp) GetLogicalRouterPortsByRouter(lr string) ([]*LogicalRouterPort, error) {
 	var lrplist = []*LogicalRouterPort{}
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 	for _, drows := range odbi.cache[tableLogicalRouter] {
 		if rlr, ok := drows.Fields["name"].(string); ok && rlr == lr {
 			ports := drows.Fields["ports"]
@@ -194,8 +167,9 @@ func (odbi *ovnDBImp) GetLogicalRouterPortsByRouter(lr string) ([]*LogicalRouter
 					if ps, ok := ports.(libovsdb.OvsSet); ok {
 						for _, p := range ps.GoSet {
 							if vp, ok := p.(libovsdb.UUID); ok {
-								tp := odbi.RowToLogicalRouterPort(vp.GoUUID)
-								lrplist = append(lrplist, tp)
+								if tp, err := odbi.RowToLogicalRouterPort(vp.GoUUID); err == nil {
+									lrplist = append(lrplist, tp)
+								}
 							}
 						}
 					} else {
@@ -203,8 +177,9 @@ func (odbi *ovnDBImp) GetLogicalRouterPortsByRouter(lr string) ([]*LogicalRouter
 					}
 				case libovsdb.UUID:
 					if vp, ok := ports.(libovsdb.UUID); ok {
-						tp := odbi.RowToLogicalRouterPort(vp.GoUUID)
-						lrplist = append(lrplist, tp)
+						if tp, err := odbi.RowToLogicalRouterPort(vp.GoUUID); err == nil {
+							lrplist = append(lrplist, tp)
+						}
 					} else {
 						return nil, fmt.Errorf("type libovsdb.UUID casting failed")
 					}
@@ -1,6 +1,10 @@
 package goovn
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestLogicalRouterPort(t *testing.T) {
 	var cmds []*OvnCommand
@@ -74,3 +78,69 @@ func TestLogicalRouterPort(t *testing.T) {
 	}
 
 }
+
+func TestLogicalRouterPortSameTransaction(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LRAdd(LR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	lrcmd := cmd
+
+	// the port is added to the router created by the same transaction
+	cmd, err = ovndbapi.LRPAdd(LR, LRP, "54:54:54:54:54:54", []string{"192.168.0.1/24"}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	lrpcmd := cmd
+
+	lrNamed, err := lrcmd.NamedUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lrpNamed, err := lrpcmd.NamedUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, lrNamed, lrpNamed, "test[%s]", "named uuids of router and port")
+
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lrUUID, err := lrcmd.UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lrpUUID, err := lrpcmd.UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var router *LogicalRouter
+	for _, lr := range ovndbapi.GetLogicalRouters() {
+		if lr.Name == LR {
+			router = lr
+		}
+	}
+	if router == nil {
+		t.Fatalf("router %s not created", LR)
+	}
+	assert.Equal(t, lrUUID, router.UUID, "test[%s]", "router uuid")
+	assert.Equal(t, []string{lrpUUID}, router.Ports, "test[%s]", "port of router created in one transaction")
+
+	cmd, err = ovndbapi.LRDel(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
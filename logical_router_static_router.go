@@ -16,11 +16,200 @@
 
 package goovn
 
+import (
+	"fmt"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// Static route policies
+const (
+	PolicySrcIP string = "src-ip"
+	PolicyDstIP string = "dst-ip"
+)
+
 type LogicalRouterStaticRoute struct {
-	UUID       string
-	IPPrefix   string
-	Policy     string
-	Nexthop    string
-	OutputPort string
-	ExternalID map[interface{}]interface{}
+	UUID       string                      `ovsdb:"_uuid"`
+	IPPrefix   string                      `ovsdb:"ip_prefix"`
+	Policy     string                      `ovsdb:"policy"`
+	Nexthop    string                      `ovsdb:"nexthop"`
+	OutputPort string                      `ovsdb:"output_port"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+// lrsrMatches reports whether the cached route is selected for deletion by
+// the given values, empty values match anything.
+func (odbi *ovnDBImp) lrsrMatches(uuid string, ip_prefix string, nexthop string) bool {
+	route, err := odbi.RowToLogicalRouterStaticRoute(uuid)
+	if err != nil {
+		return false
+	}
+	if ip_prefix != "" && route.IPPrefix != ip_prefix {
+		return false
+	}
+	if nexthop != "" && route.Nexthop != nexthop {
+		return false
+	}
+	return true
+}
+
+// lrsrEquals reports whether the cached route has exactly the given values,
+// an empty policy being dst-ip as for the database.
+func (odbi *ovnDBImp) lrsrEquals(uuid string, ip_prefix string, nexthop string, output_port string, policy string) bool {
+	route, err := odbi.RowToLogicalRouterStaticRoute(uuid)
+	if err != nil {
+		return false
+	}
+	routePolicy := route.Policy
+	if routePolicy == "" {
+		routePolicy = PolicyDstIP
+	}
+	if policy == "" {
+		policy = PolicyDstIP
+	}
+	return route.IPPrefix == ip_prefix && route.Nexthop == nexthop && route.OutputPort == output_port && routePolicy == policy
+}
+
+func (odbi *ovnDBImp) lrsrAddImp(lr string, ip_prefix string, nexthop string, output_port string, policy string, external_ids map[string]string) (*OvnCommand, error) {
+	switch policy {
+	case "", PolicySrcIP, PolicyDstIP:
+	default:
+		return nil, fmt.Errorf("unsupported static route policy %s", policy)
+	}
+
+	odbi.cachemutex.RLock()
+	_, routes, err := odbi.getRowRefsByName(tableLogicalRouter, lr, "static_routes")
+	if err == nil {
+		for _, route := range routes {
+			if odbi.lrsrEquals(route, ip_prefix, nexthop, output_port, policy) {
+				err = ErrorExist
+				break
+			}
+		}
+	}
+	odbi.cachemutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	namedUUID, err := newRowUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := odbi.encodeRow(tableLogicalRouterStaticRoute, &LogicalRouterStaticRoute{
+		IPPrefix:   ip_prefix,
+		Policy:     policy,
+		Nexthop:    nexthop,
+		OutputPort: output_port,
+		ExternalID: toModelMap(external_ids),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	insertOp := libovsdb.Operation{
+		Op:       opInsert,
+		Table:    tableLogicalRouterStaticRoute,
+		Row:      row,
+		UUIDName: namedUUID,
+	}
+
+	mutateUUID := []libovsdb.UUID{{GoUUID: namedUUID}}
+	mutateSet, err := libovsdb.NewOvsSet(mutateUUID)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("static_routes", opInsert, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", lr)
+
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalRouter,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{insertOp, mutateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+// lrsrDelImp deletes static routes of lr. With no ip_prefix all routes of lr
+// are deleted, with no nexthop all ecmp routes of ip_prefix.
+func (odbi *ovnDBImp) lrsrDelImp(lr string, ip_prefix string, nexthop string) (*OvnCommand, error) {
+	odbi.cachemutex.RLock()
+	var routeUUIDs []libovsdb.UUID
+	lrUUID, routes, err := odbi.getRowRefsByName(tableLogicalRouter, lr, "static_routes")
+	if err == nil {
+		for _, route := range routes {
+			if odbi.lrsrMatches(route, ip_prefix, nexthop) {
+				routeUUIDs = append(routeUUIDs, libovsdb.UUID{GoUUID: route})
+			}
+		}
+	}
+	odbi.cachemutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(routeUUIDs) == 0 {
+		return nil, ErrorNotFound
+	}
+
+	mutateSet, err := libovsdb.NewOvsSet(routeUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("static_routes", opDelete, mutateSet)
+	mucondition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: lrUUID})
+
+	// simple mutate operation
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalRouter,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{mucondition},
+	}
+	operations := []libovsdb.Operation{mutateOp}
+
+	for _, routeUUID := range routeUUIDs {
+		condition := libovsdb.NewCondition("_uuid", "==", routeUUID)
+		deleteOp := libovsdb.Operation{
+			Op:    opDelete,
+			Table: tableLogicalRouterStaticRoute,
+			Where: []interface{}{condition},
+		}
+		operations = append(operations, deleteOp)
+	}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) RowToLogicalRouterStaticRoute(uuid string) (*LogicalRouterStaticRoute, error) {
+	lrsr := &LogicalRouterStaticRoute{}
+	if err := decodeRow(uuid, odbi.cache[tableLogicalRouterStaticRoute][uuid].Fields, lrsr); err != nil {
+		return nil, err
+	}
+
+	if lrsr.Policy == "" {
+		lrsr.Policy = PolicyDstIP
+	}
+
+	return lrsr, nil
+}
+
+// Get all static routes by lr
+func (odbi *ovnDBImp) GetLogicalRouterStaticRoutes(lr string) ([]*LogicalRouterStaticRoute, error) {
+	var lrsrlist = []*LogicalRouterStaticRoute{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	_, routes, err := odbi.getRowRefsByName(tableLogicalRouter, lr, "static_routes")
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range routes {
+		if _, ok := odbi.cache[tableLogicalRouterStaticRoute][route]; ok {
+			if lrsr, err := odbi.RowToLogicalRouterStaticRoute(route); err == nil {
+				lrsrlist = append(lrsrlist, lrsr)
+			}
+		}
+	}
+	return lrsrlist, nil
 }
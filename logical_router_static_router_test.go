@@ -0,0 +1,111 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogicalRouterStaticRoute(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LRAdd(LR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LRPAdd(LR, LRP, "54:54:54:54:54:54", []string{"192.168.0.1/24"}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.LRSRAdd(LR, "10.0.0.0/24", "192.168.0.10", LRP, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ecmp route with same prefix and another nexthop
+	cmd, err = ovndbapi.LRSRAdd(LR, "10.0.0.0/24", "192.168.0.11", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.LRSRAdd(LR, "172.16.0.0/16", "192.168.0.12", "", "src-ip", map[string]string{"A": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.LRSRAdd(LR, "10.0.0.0/24", "192.168.0.10", LRP, "", nil)
+	assert.Equal(t, ErrorExist, err, "test[%s]", "add same route twice")
+	_, err = ovndbapi.LRSRAdd(LR, "10.0.0.0/24", "192.168.0.10", LRP, "dst-ip", nil)
+	assert.Equal(t, ErrorExist, err, "test[%s]", "add same route with default policy")
+	_, err = ovndbapi.LRSRAdd(LR, "10.0.0.0/24", "192.168.0.11", LRP, "", nil)
+	assert.Nil(t, err, "test[%s]", "add route differing by output port")
+	_, err = ovndbapi.LRSRAdd(LR, "10.0.0.0/24", "192.168.0.10", "", "", nil)
+	assert.Nil(t, err, "test[%s]", "add route without the output port of another")
+
+	_, err = ovndbapi.LRSRAdd(LR, "10.1.0.0/24", "192.168.0.10", "", "any-ip", nil)
+	assert.Equal(t, true, err != nil, "test[%s]", "invalid policy rejected")
+
+	routes, err := ovndbapi.GetLogicalRouterStaticRoutes(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(routes) == 3, "test[%s]: %v", "added static routes", routes)
+	for _, route := range routes {
+		switch route.Nexthop {
+		case "192.168.0.10":
+			assert.Equal(t, true, route.OutputPort == LRP && route.Policy == "dst-ip", "test[%s]: %v", "route with output port", route)
+		case "192.168.0.12":
+			assert.Equal(t, true, route.Policy == "src-ip", "test[%s]: %v", "route with src-ip policy", route)
+		}
+	}
+
+	lrs := ovndbapi.GetLogicalRouters()
+	assert.Equal(t, true, len(lrs) == 1 && len(lrs[0].StaticRoutes) == 3, "test[%s]: %v", "router static_routes column", lrs)
+
+	cmd, err = ovndbapi.LRSRDel(LR, "10.0.0.0/24", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err = ovndbapi.GetLogicalRouterStaticRoutes(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(routes) == 1 && routes[0].IPPrefix == "172.16.0.0/16", "test[%s]: %v", "ecmp routes removed", routes)
+
+	cmd, err = ovndbapi.LRDel(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -21,9 +21,9 @@ import (
 )
 
 type LogicalSwitch struct {
-	UUID       string
-	Name       string
-	ExternalID map[interface{}]interface{}
+	UUID       string                      `ovsdb:"_uuid"`
+	Name       string                      `ovsdb:"name"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
 }
 
 func (odbi *ovnDBImp) lswListImp() (*OvnCommand, error) {
@@ -73,22 +73,23 @@ func (odbi *ovnDBImp) lswDelImp(lsw string) (*OvnCommand, error) {
 	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
 }
 
-func (odbi *ovnDBImp) RowToLogicalSwitch(uuid string) *LogicalSwitch {
-	ls := &LogicalSwitch{
-		UUID:       uuid,
-		Name:       odbi.cache[tableLogicalSwitch][uuid].Fields["name"].(string),
-		ExternalID: odbi.cache[tableLogicalSwitch][uuid].Fields["external_ids"].(libovsdb.OvsMap).GoMap,
+func (odbi *ovnDBImp) RowToLogicalSwitch(uuid string) (*LogicalSwitch, error) {
+	ls := &LogicalSwitch{}
+	if err := decodeRow(uuid, odbi.cache[tableLogicalSwitch][uuid].Fields, ls); err != nil {
+		return nil, err
 	}
-	return ls
+	return ls, nil
 }
 
 // Get all logical switches
 func (odbi *ovnDBImp) GetLogicSwitches() []*LogicalSwitch {
 	var lslist = []*LogicalSwitch{}
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 	for uuid, _ := range odbi.cache[tableLogicalSwitch] {
-		lslist = append(lslist, odbi.RowToLogicalSwitch(uuid))
+		if ls, err := odbi.RowToLogicalSwitch(uuid); err == nil {
+			lslist = append(lslist, ls)
+		}
 	}
 	return lslist
 }
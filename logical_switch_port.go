@@ -23,15 +23,15 @@ import (
 )
 
 type LogicalSwitchPort struct {
-	UUID          string
-	Name          string
-	Type          string
-	Options       map[interface{}]interface{}
-	Addresses     []string
-	PortSecurity  []string
-	DHCPv4Options string
-	DHCPv6Options string
-	ExternalID    map[interface{}]interface{}
+	UUID          string                      `ovsdb:"_uuid"`
+	Name          string                      `ovsdb:"name"`
+	Type          string                      `ovsdb:"type"`
+	Options       map[interface{}]interface{} `ovsdb:"options"`
+	Addresses     []string                    `ovsdb:"addresses"`
+	PortSecurity  []string                    `ovsdb:"port_security"`
+	DHCPv4Options string                      `ovsdb:"dhcpv4_options"`
+	DHCPv6Options string                      `ovsdb:"dhcpv6_options"`
+	ExternalID    map[interface{}]interface{} `ovsdb:"external_ids"`
 }
 
 func (odbi *ovnDBImp) lspAddImp(lsw, lsp string) (*OvnCommand, error) {
@@ -111,12 +111,10 @@ func (odbi *ovnDBImp) lspDelImp(lsp string) (*OvnCommand, error) {
 }
 
 func (odbi *ovnDBImp) lspSetAddressImp(lsp string, addr ...string) (*OvnCommand, error) {
-	row := make(OVNRow)
-	addresses, err := libovsdb.NewOvsSet(addr)
+	row, err := odbi.encodeRow(tableLogicalSwitchPort, &LogicalSwitchPort{Addresses: addr}, "addresses")
 	if err != nil {
 		return nil, err
 	}
-	row["addresses"] = addresses
 	condition := libovsdb.NewCondition("name", "==", lsp)
 	updateOp := libovsdb.Operation{
 		Op:    opUpdate,
@@ -129,12 +127,10 @@ func (odbi *ovnDBImp) lspSetAddressImp(lsp string, addr ...string) (*OvnCommand,
 }
 
 func (odbi *ovnDBImp) lspSetPortSecurityImp(lsp string, security ...string) (*OvnCommand, error) {
-	row := make(OVNRow)
-	port_security, err := libovsdb.NewOvsSet(security)
+	row, err := odbi.encodeRow(tableLogicalSwitchPort, &LogicalSwitchPort{PortSecurity: security}, "port_security")
 	if err != nil {
 		return nil, err
 	}
-	row["port_security"] = port_security
 	condition := libovsdb.NewCondition("name", "==", lsp)
 	updateOp := libovsdb.Operation{
 		Op:    opUpdate,
@@ -165,19 +161,20 @@ func (odbi *ovnDBImp) LSPGetDHCPv4Options(lsp string) (*DHCPOptions, error) {
 	if err != nil {
 		return nil, err
 	}
-	return odbi.RowToDHCPOptions(lp.DHCPv4Options), nil
+	return odbi.RowToDHCPOptions(lp.DHCPv4Options)
 }
 
-func (odbi *ovnDBImp) LSPSetDHCPv6Options(lsp string, options string) (*OvnCommand, error) {
-	mutation := libovsdb.NewMutation("dhcpv6_options", opInsert, options)
+func (odbi *ovnDBImp) LSPSetDHCPv6Options(lsp string, uuid string) (*OvnCommand, error) {
+	row := make(OVNRow)
+	row["dhcpv6_options"] = libovsdb.UUID{GoUUID: uuid}
 	condition := libovsdb.NewCondition("name", "==", lsp)
-	mutateOp := libovsdb.Operation{
-		Op:        opMutate,
-		Table:     tableLogicalSwitchPort,
-		Mutations: []interface{}{mutation},
-		Where:     []interface{}{condition},
+	updateOp := libovsdb.Operation{
+		Op:    opUpdate,
+		Table: tableLogicalSwitchPort,
+		Row:   row,
+		Where: []interface{}{condition},
 	}
-	operations := []libovsdb.Operation{mutateOp}
+	operations := []libovsdb.Operation{updateOp}
 	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
 }
 
@@ -186,7 +183,7 @@ func (odbi *ovnDBImp) LSPGetDHCPv6Options(lsp string) (*DHCPOptions, error) {
 	if err != nil {
 		return nil, err
 	}
-	return odbi.RowToDHCPOptions(lp.DHCPv6Options), nil
+	return odbi.RowToDHCPOptions(lp.DHCPv6Options)
 }
 
 func (odbi *ovnDBImp) LSPSetOpt(lsp string, options map[string]string) (*OvnCommand, error) {
@@ -205,60 +202,21 @@ func (odbi *ovnDBImp) LSPSetOpt(lsp string, options map[string]string) (*OvnComm
 	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
 }
 
-func (odbi *ovnDBImp) RowToLogicalPort(uuid string) *LogicalSwitchPort {
-	lp := &LogicalSwitchPort{
-		UUID: uuid,
-		Name: odbi.cache[tableLogicalSwitchPort][uuid].Fields["name"].(string),
-	}
-
-	if dhcpv4, ok := odbi.cache[tableLogicalSwitchPort][uuid].Fields["dhcpv4_options"]; ok {
-		switch dhcpv4.(type) {
-		case libovsdb.UUID:
-			lp.DHCPv4Options = dhcpv4.(libovsdb.UUID).GoUUID
-		case libovsdb.OvsSet:
-		default:
-		}
-	}
-	if dhcpv6, ok := odbi.cache[tableLogicalSwitchPort][uuid].Fields["dhcpv6_options"]; ok {
-		switch dhcpv6.(type) {
-		case libovsdb.UUID:
-			lp.DHCPv6Options = dhcpv6.(libovsdb.UUID).GoUUID
-		case libovsdb.OvsSet:
-		default:
-		}
-	}
-
-	if addr, ok := odbi.cache[tableLogicalSwitchPort][uuid].Fields["addresses"]; ok {
-		switch addr.(type) {
-		case string:
-			lp.Addresses = []string{addr.(string)}
-		case libovsdb.OvsSet:
-			lp.Addresses = odbi.ConvertGoSetToStringArray(addr.(libovsdb.OvsSet))
-		default:
-			//	glog.V(OVNLOGLEVEL).Info("Unsupport type found in lport address.")
-		}
-	}
-
-	if portsecurity, ok := odbi.cache[tableLogicalSwitchPort][uuid].Fields["port_security"]; ok {
-		switch portsecurity.(type) {
-		case string:
-			lp.PortSecurity = []string{portsecurity.(string)}
-		case libovsdb.OvsSet:
-			lp.PortSecurity = odbi.ConvertGoSetToStringArray(portsecurity.(libovsdb.OvsSet))
-		default:
-			//glog.V(OVNLOGLEVEL).Info("Unsupport type found in lport port security.")
-		}
+func (odbi *ovnDBImp) RowToLogicalPort(uuid string) (*LogicalSwitchPort, error) {
+	lp := &LogicalSwitchPort{}
+	if err := decodeRow(uuid, odbi.cache[tableLogicalSwitchPort][uuid].Fields, lp); err != nil {
+		return nil, err
 	}
-	return lp
+	return lp, nil
 }
 
 // Get lsp by name
 func (odbi *ovnDBImp) GetLogicalPortByName(lsp string) (*LogicalSwitchPort, error) {
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 	for uuid, drows := range odbi.cache[tableLogicalSwitchPort] {
 		if rlsp, ok := drows.Fields["name"].(string); ok && rlsp == lsp {
-			return odbi.RowToLogicalPort(uuid), nil
+			return odbi.RowToLogicalPort(uuid)
 		}
 	}
 	return nil, ErrorNotFound
@@ -267,8 +225,8 @@ func (odbi *ovnDBImp) GetLogicalPortByName(lsp string) (*LogicalSwitchPort, erro
 // Get all lport by lswitch
 func (odbi *ovnDBImp) GetLogicPortsBySwitch(lsw string) ([]*LogicalSwitchPort, error) {
 	var lplist = []*LogicalSwitchPort{}
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 	for _, drows := range odbi.cache[tableLogicalSwitch] {
 		if rlsw, ok := drows.Fields["name"].(string); ok && rlsw == lsw {
 			ports := drows.Fields["ports"]
@@ -278,8 +236,9 @@ func (odbi *ovnDBImp) GetLogicPortsBySwitch(lsw string) ([]*LogicalSwitchPort, e
 					if ps, ok := ports.(libovsdb.OvsSet); ok {
 						for _, p := range ps.GoSet {
 							if vp, ok := p.(libovsdb.UUID); ok {
-								tp := odbi.RowToLogicalPort(vp.GoUUID)
-								lplist = append(lplist, tp)
+								if tp, err := odbi.RowToLogicalPort(vp.GoUUID); err == nil {
+									lplist = append(lplist, tp)
+								}
 							}
 						}
 					} else {
@@ -287,8 +246,9 @@ func (odbi *ovnDBImp) GetLogicPortsBySwitch(lsw string) ([]*LogicalSwitchPort, e
 					}
 				case libovsdb.UUID:
 					if vp, ok := ports.(libovsdb.UUID); ok {
-						tp := odbi.RowToLogicalPort(vp.GoUUID)
-						lplist = append(lplist, tp)
+						if tp, err := odbi.RowToLogicalPort(vp.GoUUID); err == nil {
+							lplist = append(lplist, tp)
+						}
 					} else {
 						return nil, fmt.Errorf("type libovsdb.UUID casting failed")
 					}
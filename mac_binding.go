@@ -0,0 +1,46 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+type MACBinding struct {
+	UUID        string `ovsdb:"_uuid"`
+	LogicalPort string `ovsdb:"logical_port"`
+	IP          string `ovsdb:"ip"`
+	MAC         string `ovsdb:"mac"`
+	Datapath    string `ovsdb:"datapath"`
+}
+
+func (odbi *ovnDBImp) RowToMACBinding(uuid string) (*MACBinding, error) {
+	mb := &MACBinding{}
+	if err := decodeRow(uuid, odbi.cache[tableMACBinding][uuid].Fields, mb); err != nil {
+		return nil, err
+	}
+	return mb, nil
+}
+
+// Get all mac bindings
+func (odbi *ovnDBImp) GetMACBindings() []*MACBinding {
+	var mblist = []*MACBinding{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid := range odbi.cache[tableMACBinding] {
+		if mb, err := odbi.RowToMACBinding(uuid); err == nil {
+			mblist = append(mblist, mb)
+		}
+	}
+	return mblist
+}
@@ -0,0 +1,196 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"fmt"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// Meter units
+const (
+	MeterUnitKbps  string = "kbps"
+	MeterUnitPktps string = "pktps"
+)
+
+// Meter band actions
+const (
+	MeterBandActionDrop string = "drop"
+)
+
+type MeterBand struct {
+	UUID       string                      `ovsdb:"_uuid"`
+	Action     string                      `ovsdb:"action"`
+	Rate       int                         `ovsdb:"rate"`
+	BurstSize  int                         `ovsdb:"burst_size"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+type Meter struct {
+	UUID       string `ovsdb:"_uuid"`
+	Name       string `ovsdb:"name"`
+	Unit       string `ovsdb:"unit"`
+	Bands      []*MeterBand
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+func (odbi *ovnDBImp) meterAddImp(name string, unit string, bands []*MeterBand, external_ids map[string]string) (*OvnCommand, error) {
+	switch unit {
+	case MeterUnitKbps, MeterUnitPktps:
+	default:
+		return nil, fmt.Errorf("unsupported meter unit %s", unit)
+	}
+	if len(bands) == 0 {
+		return nil, fmt.Errorf("meter %s requires at least one band", name)
+	}
+
+	row := make(OVNRow)
+	row["name"] = name
+
+	if uuid := odbi.getRowUUID(tableMeter, row); len(uuid) > 0 {
+		return nil, ErrorExist
+	}
+
+	var operations []libovsdb.Operation
+	var bandUUIDs []libovsdb.UUID
+	for _, band := range bands {
+		action := band.Action
+		if action == "" {
+			action = MeterBandActionDrop
+		}
+		if action != MeterBandActionDrop {
+			return nil, fmt.Errorf("unsupported meter band action %s", action)
+		}
+		if band.Rate <= 0 || band.BurstSize < 0 {
+			return nil, fmt.Errorf("invalid meter band rate %d burst %d", band.Rate, band.BurstSize)
+		}
+
+		namedUUID, err := newRowUUID()
+		if err != nil {
+			return nil, err
+		}
+
+		bandRow, err := odbi.encodeRow(tableMeterBand, &MeterBand{
+			Action:     action,
+			Rate:       band.Rate,
+			BurstSize:  band.BurstSize,
+			ExternalID: band.ExternalID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		insertOp := libovsdb.Operation{
+			Op:       opInsert,
+			Table:    tableMeterBand,
+			Row:      bandRow,
+			UUIDName: namedUUID,
+		}
+		operations = append(operations, insertOp)
+		bandUUIDs = append(bandUUIDs, libovsdb.UUID{GoUUID: namedUUID})
+	}
+
+	namedUUID, err := newRowUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	row, err = odbi.encodeRow(tableMeter, &Meter{
+		Name:       name,
+		Unit:       unit,
+		ExternalID: toModelMap(external_ids),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the bands are not a column of the model, they are decoded as rows
+	bandSet, err := libovsdb.NewOvsSet(bandUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	row["bands"] = bandSet
+
+	insertOp := libovsdb.Operation{
+		Op:       opInsert,
+		Table:    tableMeter,
+		Row:      row,
+		UUIDName: namedUUID,
+	}
+	operations = append(operations, insertOp)
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+// meterDelImp deletes the meter with given name, its bands are garbage
+// collected by the database.
+func (odbi *ovnDBImp) meterDelImp(name string) (*OvnCommand, error) {
+	row := make(OVNRow)
+	row["name"] = name
+
+	if uuid := odbi.getRowUUID(tableMeter, row); len(uuid) == 0 {
+		return nil, ErrorNotFound
+	}
+
+	condition := libovsdb.NewCondition("name", "==", name)
+	deleteOp := libovsdb.Operation{
+		Op:    opDelete,
+		Table: tableMeter,
+		Where: []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{deleteOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) RowToMeterBand(uuid string) (*MeterBand, error) {
+	band := &MeterBand{}
+	if err := decodeRow(uuid, odbi.cache[tableMeterBand][uuid].Fields, band); err != nil {
+		return nil, err
+	}
+	return band, nil
+}
+
+func (odbi *ovnDBImp) RowToMeter(uuid string) (*Meter, error) {
+	meter := &Meter{}
+	if err := decodeRow(uuid, odbi.cache[tableMeter][uuid].Fields, meter); err != nil {
+		return nil, err
+	}
+
+	for _, band := range odbi.getRefUUIDs(odbi.cache[tableMeter][uuid].Fields["bands"]) {
+		if _, ok := odbi.cache[tableMeterBand][band]; ok {
+			b, err := odbi.RowToMeterBand(band)
+			if err != nil {
+				return nil, err
+			}
+			meter.Bands = append(meter.Bands, b)
+		}
+	}
+
+	return meter, nil
+}
+
+// Get all meters
+func (odbi *ovnDBImp) GetMeters() []*Meter {
+	var meterlist = []*Meter{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid := range odbi.cache[tableMeter] {
+		if meter, err := odbi.RowToMeter(uuid); err == nil {
+			meterlist = append(meterlist, meter)
+		}
+	}
+	return meterlist
+}
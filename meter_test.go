@@ -0,0 +1,144 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	METER = "TEST_METER"
+)
+
+func TestMeter(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.MeterAdd(METER, "pktps", []*MeterBand{{Rate: 100, BurstSize: 10}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.MeterAdd(METER, "pktps", []*MeterBand{{Rate: 100}}, nil)
+	assert.Equal(t, ErrorExist, err, "test[%s]", "add same meter twice")
+
+	_, err = ovndbapi.MeterAdd("TEST_METER_SECOND", "bps", []*MeterBand{{Rate: 100}}, nil)
+	assert.Equal(t, true, err != nil, "test[%s]", "invalid unit rejected")
+
+	meters := ovndbapi.GetMeters()
+	assert.Equal(t, true, len(meters) == 1 && meters[0].Name == METER && meters[0].Unit == "pktps", "test[%s]: %v", "meter added", meters)
+	assert.Equal(t, true, len(meters[0].Bands) == 1 && meters[0].Bands[0].Action == "drop" &&
+		meters[0].Bands[0].Rate == 100 && meters[0].Bands[0].BurstSize == 10, "test[%s]: %v", "meter band added", meters[0].Bands)
+
+	cmd, err = ovndbapi.ACLAdd(LSW, "to-lport", MATCH, "drop", 1001, nil, true, METER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.LSWDel(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.MeterDel(METER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meters = ovndbapi.GetMeters()
+	assert.Equal(t, true, len(meters) == 0, "test[%s]: %v", "meter removed", meters)
+
+	_, err = ovndbapi.MeterDel(METER)
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "delete missing meter")
+}
+
+func TestMeterSameTransaction(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.MeterAdd(METER, "kbps", []*MeterBand{{Rate: 1000}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	metercmd := cmd
+
+	// the acl logs through the meter created by the same transaction
+	cmd, err = ovndbapi.ACLAdd(LSW, "to-lport", MATCH, "drop", 1001, nil, true, METER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	// the meter row is inserted after its bands
+	named, err := metercmd.NamedUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := metercmd.Operations[len(metercmd.Operations)-1]
+	assert.Equal(t, true, last.Table == tableMeter && last.UUIDName == named, "test[%s]", "named uuid of the meter")
+
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meterUUID, err := metercmd.UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	meters := ovndbapi.GetMeters()
+	assert.Equal(t, true, len(meters) == 1 && meters[0].UUID == meterUUID, "test[%s]: %v", "meter added", meters)
+	acls := ovndbapi.GetACLsBySwitch(LSW)
+	assert.Equal(t, true, len(acls) == 1 && acls[0].Log && acls[0].Meter == METER, "test[%s]: %v", "acl metered in one transaction", acls)
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LSWDel(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.MeterDel(METER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,167 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package mock provides an OVNDBApi on an in-memory northbound database,
+// for the unit tests of code using the client.
+package mock
+
+import (
+	"context"
+	"sync"
+
+	goovn "github.com/ebay/go-ovn"
+	"github.com/ebay/go-ovn/ovsdbtest"
+	"github.com/unistack-org/libovsdb"
+)
+
+// OVNDB is a regular client, with its cache, callbacks and subscriptions,
+// connected to an ovsdbtest.Server of its own. The operations of every
+// Execute are recorded and failures can be injected.
+type OVNDB struct {
+	goovn.OVNDBApi
+	server     *ovsdbtest.Server
+	mutex      sync.Mutex
+	operations []libovsdb.Operation
+	failures   []failure
+}
+
+type failure struct {
+	op    string
+	table string
+	err   error
+}
+
+// NewClient returns a client of an empty in-memory northbound database.
+// The connection fields of cfg are ignored.
+func NewClient(cfg goovn.Config) (*OVNDB, error) {
+	server, err := ovsdbtest.NewNBServer()
+	if err != nil {
+		return nil, err
+	}
+	api, err := goovn.NewClientConn(context.Background(), server.Pipe(), cfg)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	return &OVNDB{OVNDBApi: api, server: server}, nil
+}
+
+func (m *OVNDB) Execute(cmds ...*goovn.OvnCommand) error {
+	return m.ExecuteContext(context.Background(), cmds...)
+}
+
+// ExecuteContext records the operations of cmds and fails them as told by
+// FailOperation, otherwise they are committed and the callbacks notified
+// before it returns.
+func (m *OVNDB) ExecuteContext(ctx context.Context, cmds ...*goovn.OvnCommand) error {
+	var ops []libovsdb.Operation
+	for _, cmd := range cmds {
+		if cmd != nil {
+			ops = append(ops, cmd.Operations...)
+		}
+	}
+	m.mutex.Lock()
+	m.operations = append(m.operations, ops...)
+	err := m.failure(cmds, ops)
+	m.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.OVNDBApi.ExecuteContext(ctx, cmds...)
+}
+
+// failure returns the error injected for the first matching operation of
+// ops, nil if there is none.
+func (m *OVNDB) failure(cmds []*goovn.OvnCommand, ops []libovsdb.Operation) error {
+	for i, op := range ops {
+		for _, f := range m.failures {
+			if (f.op != "" && f.op != op.Op) || (f.table != "" && f.table != op.Table) {
+				continue
+			}
+			txerr := &goovn.TransactionError{
+				Code:      f.err.Error(),
+				Details:   "failure injected by the mock",
+				Index:     i,
+				Operation: &ops[i],
+				Command:   commandOfOperation(cmds, i),
+			}
+			// the sentinel of a transaction error has its code as message
+			if txerr.Unwrap() == f.err {
+				return txerr
+			}
+			return f.err
+		}
+	}
+	return nil
+}
+
+// commandOfOperation returns the command of the operation at index in the
+// transaction of cmds
+func commandOfOperation(cmds []*goovn.OvnCommand, index int) *goovn.OvnCommand {
+	for _, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		if index < len(cmd.Operations) {
+			return cmd
+		}
+		index -= len(cmd.Operations)
+	}
+	return nil
+}
+
+// Seed commits cmds without recording them nor applying the failures, to
+// fill the database before the test.
+func (m *OVNDB) Seed(cmds ...*goovn.OvnCommand) error {
+	return m.OVNDBApi.Execute(cmds...)
+}
+
+// FailOperation makes the transactions with an operation op ("insert",
+// "mutate"...) on table fail until ClearFailures, an empty op or table
+// matching any. A transaction error such as ErrorConstraintViolation is
+// returned as a *TransactionError of the operation, other errors as is.
+func (m *OVNDB) FailOperation(op, table string, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.failures = append(m.failures, failure{op, table, err})
+}
+
+func (m *OVNDB) ClearFailures() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.failures = nil
+}
+
+// Operations returns the operations of every Execute since the last
+// ClearOperations, failed ones included.
+func (m *OVNDB) Operations() []libovsdb.Operation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]libovsdb.Operation{}, m.operations...)
+}
+
+func (m *OVNDB) ClearOperations() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.operations = nil
+}
+
+// Close closes the client and its database
+func (m *OVNDB) Close() error {
+	err := m.OVNDBApi.Close()
+	m.server.Close()
+	return err
+}
@@ -0,0 +1,98 @@
+package mock
+
+import (
+	"errors"
+	"testing"
+
+	goovn "github.com/ebay/go-ovn"
+	"github.com/stretchr/testify/assert"
+)
+
+// lswRecorder records the changes of logical switches
+type lswRecorder struct {
+	created []string
+	deleted []string
+	updated [][2]string
+}
+
+func (r *lswRecorder) OnLogicalSwitchCreate(ls *goovn.LogicalSwitch) {
+	r.created = append(r.created, ls.Name)
+}
+func (r *lswRecorder) OnLogicalSwitchDelete(ls *goovn.LogicalSwitch) {
+	r.deleted = append(r.deleted, ls.Name)
+}
+func (r *lswRecorder) OnLogicalSwitchUpdate(old, new *goovn.LogicalSwitch) {
+	r.updated = append(r.updated, [2]string{old.Name, new.Name})
+}
+func (r *lswRecorder) OnLogicalPortCreate(lp *goovn.LogicalSwitchPort)             {}
+func (r *lswRecorder) OnLogicalPortDelete(lp *goovn.LogicalSwitchPort)             {}
+func (r *lswRecorder) OnLogicalPortUpdate(old, new *goovn.LogicalSwitchPort)       {}
+func (r *lswRecorder) OnLogicalRouterCreate(lr *goovn.LogicalRouter)               {}
+func (r *lswRecorder) OnLogicalRouterDelete(lr *goovn.LogicalRouter)               {}
+func (r *lswRecorder) OnLogicalRouterUpdate(old, new *goovn.LogicalRouter)         {}
+func (r *lswRecorder) OnLogicalRouterPortCreate(lrp *goovn.LogicalRouterPort)      {}
+func (r *lswRecorder) OnLogicalRouterPortDelete(lrp *goovn.LogicalRouterPort)      {}
+func (r *lswRecorder) OnLogicalRouterPortUpdate(old, new *goovn.LogicalRouterPort) {}
+func (r *lswRecorder) OnACLCreate(acl *goovn.ACL)                                  {}
+func (r *lswRecorder) OnACLDelete(acl *goovn.ACL)                                  {}
+func (r *lswRecorder) OnACLUpdate(old, new *goovn.ACL)                             {}
+func (r *lswRecorder) OnDHCPOptionsCreate(dhcp *goovn.DHCPOptions)                 {}
+func (r *lswRecorder) OnDHCPOptionsDelete(dhcp *goovn.DHCPOptions)                 {}
+func (r *lswRecorder) OnDHCPOptionsUpdate(old, new *goovn.DHCPOptions)             {}
+
+func TestMockClient(t *testing.T) {
+	recorder := &lswRecorder{}
+	var api goovn.OVNDBApi
+	mock, err := NewClient(goovn.Config{SignalCB: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	api = mock
+
+	cmd, err := api.LSWAdd("ls1")
+	assert.Nil(t, err, "test[%s]", "LSWAdd")
+	assert.Nil(t, mock.Seed(cmd), "test[%s]", "seed switch")
+	cmd, err = api.LSPAdd("ls1", "lsp1")
+	assert.Nil(t, err, "test[%s]", "LSPAdd")
+	assert.Nil(t, mock.Seed(cmd), "test[%s]", "seed port")
+	assert.Len(t, mock.Operations(), 0, "test[%s]", "seeding not recorded")
+
+	cmd, err = api.ACLAdd("ls1", "to-lport", "outport == \"lsp1\"", "drop", 1001, nil, false, "")
+	assert.Nil(t, err, "test[%s]", "ACLAdd")
+	assert.Nil(t, api.Execute(cmd), "test[%s]", "execute ACLAdd")
+	assert.Equal(t, cmd.Operations, mock.Operations(), "test[%s]", "operations recorded")
+	assert.Len(t, api.GetACLsBySwitch("ls1"), 1, "test[%s]", "acl in cache")
+	ports, err := api.GetLogicPortsBySwitch("ls1")
+	assert.Nil(t, err, "test[%s]", "GetLogicPortsBySwitch")
+	assert.Len(t, ports, 1, "test[%s]", "port in cache")
+
+	mock.ClearOperations()
+	mock.FailOperation("insert", "Logical_Switch", goovn.ErrorConstraintViolation)
+	cmd, err = api.LSWAdd("ls2")
+	assert.Nil(t, err, "test[%s]", "LSWAdd")
+	err = api.Execute(cmd)
+	txerr, ok := err.(*goovn.TransactionError)
+	if assert.True(t, ok, "test[%s]", "injected transaction error") {
+		assert.Equal(t, goovn.ErrorConstraintViolation, txerr.Unwrap(), "test[%s]", "injected code")
+		assert.Equal(t, cmd, txerr.Command, "test[%s]", "failed command")
+	}
+	assert.Equal(t, cmd.Operations, mock.Operations(), "test[%s]", "failed operations recorded")
+	assert.Len(t, api.GetLogicSwitches(), 1, "test[%s]", "failed command not committed")
+
+	failure := errors.New("connection lost")
+	mock.ClearFailures()
+	mock.FailOperation("", "", failure)
+	assert.Equal(t, failure, api.Execute(cmd), "test[%s]", "injected error")
+	mock.ClearFailures()
+	assert.Nil(t, api.Execute(cmd), "test[%s]", "failures cleared")
+
+	cmd, err = api.LSWDel("ls1")
+	assert.Nil(t, err, "test[%s]", "LSWDel")
+	assert.Nil(t, api.Execute(cmd), "test[%s]", "execute LSWDel")
+	assert.Equal(t, []string{"ls1", "ls2"}, recorder.created, "test[%s]", "created switches signaled")
+	assert.Equal(t, []string{"ls1"}, recorder.deleted, "test[%s]", "deleted switch signaled")
+	assert.Equal(t, [][2]string{{"ls1", "ls1"}, {"ls1", "ls1"}}, recorder.updated, "test[%s]", "port and acl added to the switch")
+	ports, _ = api.GetLogicPortsBySwitch("ls1")
+	assert.Len(t, ports, 0, "test[%s]", "ports deleted with the switch")
+}
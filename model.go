@@ -0,0 +1,381 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// A model is a struct whose fields are mapped to the columns of a table by
+// their ovsdb tag, as `ovsdb:"external_ids"`, or `ovsdb:"_uuid"` for the
+// uuid of the row. Untagged fields are left alone. A set column is read into
+// a slice, or a scalar or a pointer when it holds at most one value; a map
+// column into a Go map; references as their uuid strings. An empty optional
+// column is the zero value of a scalar, and an empty string is written as
+// one. With the omitempty option a zero field is not written to an inserted
+// row.
+
+type modelField struct {
+	index     int
+	column    string
+	omitempty bool
+}
+
+// modelFields caches the fields of the model types by type
+var modelFields sync.Map
+
+func getModelFields(t reflect.Type) []modelField {
+	if fields, ok := modelFields.Load(t); ok {
+		return fields.([]modelField)
+	}
+	var fields []modelField
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("ovsdb")
+		if !ok || tag == "-" || t.Field(i).PkgPath != "" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		f := modelField{index: i, column: parts[0]}
+		for _, option := range parts[1:] {
+			if option == "omitempty" {
+				f.omitempty = true
+			}
+		}
+		fields = append(fields, f)
+	}
+	modelFields.Store(t, fields)
+	return fields
+}
+
+// modelValue returns the struct model points to
+func modelValue(model interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("model %T is not a pointer to a struct", model)
+	}
+	return v.Elem(), nil
+}
+
+// decodeRow sets the fields of model from the columns of a row in the cache
+// or in the result of a select. Every field is decoded, the first error is
+// returned and its field left unchanged.
+func decodeRow(uuid string, fields map[string]interface{}, model interface{}) error {
+	v, err := modelValue(model)
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, f := range getModelFields(v.Type()) {
+		value, ok := fields[f.column]
+		if f.column == "_uuid" && uuid != "" {
+			value, ok = uuid, true
+		}
+		if !ok {
+			continue
+		}
+		err := decodeValue(v.Field(f.index), value)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("column %s: %v", f.column, err)
+		}
+	}
+	return firstErr
+}
+
+func decodeValue(field reflect.Value, value interface{}) error {
+	decoded := reflect.New(field.Type()).Elem()
+	switch field.Kind() {
+	case reflect.Map:
+		m, ok := value.(libovsdb.OvsMap)
+		if !ok {
+			return fmt.Errorf("%v is not a map", value)
+		}
+		if field.Type() == reflect.TypeOf(m.GoMap) {
+			field.Set(reflect.ValueOf(m.GoMap))
+			return nil
+		}
+		decoded.Set(reflect.MakeMapWithSize(field.Type(), len(m.GoMap)))
+		for k, v := range m.GoMap {
+			key := reflect.New(field.Type().Key()).Elem()
+			if err := decodeAtom(key, k); err != nil {
+				return err
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := decodeAtom(elem, v); err != nil {
+				return err
+			}
+			decoded.SetMapIndex(key, elem)
+		}
+	case reflect.Slice:
+		elements, err := setElements(value)
+		if err != nil {
+			return err
+		}
+		decoded.Set(reflect.MakeSlice(field.Type(), len(elements), len(elements)))
+		for i, e := range elements {
+			if err := decodeAtom(decoded.Index(i), e); err != nil {
+				return err
+			}
+		}
+	default:
+		elements, err := setElements(value)
+		if err != nil {
+			return err
+		}
+		if len(elements) > 1 {
+			return fmt.Errorf("%d values for a single one", len(elements))
+		}
+		if len(elements) == 1 {
+			target := decoded
+			if field.Kind() == reflect.Ptr {
+				decoded.Set(reflect.New(field.Type().Elem()))
+				target = decoded.Elem()
+			}
+			if err := decodeAtom(target, elements[0]); err != nil {
+				return err
+			}
+		}
+	}
+	field.Set(decoded)
+	return nil
+}
+
+// setElements returns the elements of a set column, which holds an atom
+// when it has a single one
+func setElements(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case libovsdb.OvsSet:
+		return v.GoSet, nil
+	case libovsdb.OvsMap:
+		return nil, fmt.Errorf("%v is not a set", value)
+	}
+	return []interface{}{value}, nil
+}
+
+func decodeAtom(dst reflect.Value, atom interface{}) error {
+	if uuid, ok := atom.(libovsdb.UUID); ok {
+		atom = uuid.GoUUID
+	}
+	src := reflect.ValueOf(atom)
+	switch dst.Kind() {
+	case reflect.Interface:
+		if src.IsValid() && src.Type().AssignableTo(dst.Type()) {
+			dst.Set(src)
+			return nil
+		}
+	case reflect.String:
+		if src.Kind() == reflect.String {
+			dst.SetString(src.String())
+			return nil
+		}
+	case reflect.Bool:
+		if src.Kind() == reflect.Bool {
+			dst.SetBool(src.Bool())
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int64:
+			dst.SetInt(src.Int())
+			return nil
+		case reflect.Float64:
+			if f := src.Float(); f == float64(int64(f)) {
+				dst.SetInt(int64(f))
+				return nil
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int64:
+			if src.Int() >= 0 {
+				dst.SetUint(uint64(src.Int()))
+				return nil
+			}
+		case reflect.Float64:
+			if f := src.Float(); f >= 0 && f == float64(uint64(f)) {
+				dst.SetUint(uint64(f))
+				return nil
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int64:
+			dst.SetFloat(float64(src.Int()))
+			return nil
+		case reflect.Float64:
+			dst.SetFloat(src.Float())
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot decode %v into %s", atom, dst.Type())
+}
+
+// encodeRow returns the row of the given columns of model, or of all its
+// columns but the zero omitempty ones when none is given. Values are
+// converted to the types of the columns in table.
+func (odbi *ovnDBImp) encodeRow(table string, model interface{}, columns ...string) (OVNRow, error) {
+	v, err := modelValue(model)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		selected[column] = true
+	}
+	row := make(OVNRow)
+	for _, f := range getModelFields(v.Type()) {
+		if f.column == "_uuid" || f.column == "_version" {
+			continue
+		}
+		field := v.Field(f.index)
+		if len(columns) > 0 && !selected[f.column] {
+			continue
+		}
+		if len(columns) == 0 && f.omitempty && reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
+			continue
+		}
+		t, err := odbi.getColumnType(table, f.column)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		switch {
+		case field.Kind() == reflect.String && field.String() == "" && t.min == 0 && t.max == 1:
+			// as it is decoded from an empty optional column
+			value = libovsdb.OvsSet{GoSet: []interface{}{}}
+		case field.Kind() != reflect.Ptr:
+			value = field.Interface()
+		case !field.IsNil():
+			value = field.Elem().Interface()
+		case t.isMap():
+			value = libovsdb.OvsMap{GoMap: map[interface{}]interface{}{}}
+		default:
+			value = libovsdb.OvsSet{GoSet: []interface{}{}}
+		}
+		row[f.column], err = t.toOvsdb(value, false)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", f.column, err)
+		}
+		delete(selected, f.column)
+	}
+	for column := range selected {
+		return nil, fmt.Errorf("column %s is not a field of %s", column, v.Type())
+	}
+	return row, nil
+}
+
+// toModelMap converts a map argument of a command to the type of the map
+// fields of the models
+func toModelMap(m map[string]string) map[interface{}]interface{} {
+	if m == nil {
+		return nil
+	}
+	converted := make(map[interface{}]interface{}, len(m))
+	for k, v := range m {
+		converted[k] = v
+	}
+	return converted
+}
+
+// GetModels sets models, a pointer to a slice of models or of pointers to
+// models, to the rows of table in the cache
+func (odbi *ovnDBImp) GetModels(table string, models interface{}) error {
+	slice := reflect.ValueOf(models)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("models %T is not a pointer to a slice", models)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("models %T is not a slice of structs", models)
+	}
+
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	result := reflect.MakeSlice(slice.Type(), 0, len(odbi.cache[table]))
+	for uuid, drows := range odbi.cache[table] {
+		model := reflect.New(elemType)
+		if err := decodeRow(uuid, drows.Fields, model.Interface()); err != nil {
+			return fmt.Errorf("row %s of %s: %v", uuid, table, err)
+		}
+		if isPtr {
+			result = reflect.Append(result, model)
+		} else {
+			result = reflect.Append(result, model.Elem())
+		}
+	}
+	slice.Set(result)
+	return nil
+}
+
+// GetModel sets model to the row of table in the cache given by record, its
+// uuid or name
+func (odbi *ovnDBImp) GetModel(table, record string, model interface{}) error {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	uuid, err := odbi.findRecord(table, record)
+	if err != nil {
+		return err
+	}
+	return decodeRow(uuid, odbi.cache[table][uuid].Fields, model)
+}
+
+func (odbi *ovnDBImp) insertModelImp(table string, model interface{}) (*OvnCommand, error) {
+	row, err := odbi.encodeRow(table, model)
+	if err != nil {
+		return nil, err
+	}
+	namedUUID, err := newRowUUID()
+	if err != nil {
+		return nil, err
+	}
+	insertOp := libovsdb.Operation{
+		Op:       opInsert,
+		Table:    table,
+		Row:      row,
+		UUIDName: namedUUID,
+	}
+	operations := []libovsdb.Operation{insertOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) updateModelImp(table, record string, model interface{}, columns ...string) (*OvnCommand, error) {
+	if len(columns) == 0 {
+		v, err := modelValue(model)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range getModelFields(v.Type()) {
+			if f.column != "_uuid" && f.column != "_version" {
+				columns = append(columns, f.column)
+			}
+		}
+	}
+	row, err := odbi.encodeRow(table, model, columns...)
+	if err != nil {
+		return nil, err
+	}
+	return odbi.updateRecord(table, record, row)
+}
@@ -0,0 +1,148 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+type testModel struct {
+	UUID      string                      `ovsdb:"_uuid"`
+	Name      string                      `ovsdb:"name"`
+	Tag       *int                        `ovsdb:"tag"`
+	Peer      string                      `ovsdb:"peer"`
+	Enabled   *bool                       `ovsdb:"enabled"`
+	Addresses []string                    `ovsdb:"addresses"`
+	Ports     []string                    `ovsdb:"ports"`
+	Bandwidth map[string]int              `ovsdb:"bandwidth"`
+	Options   map[interface{}]interface{} `ovsdb:"options"`
+	Ignored   string
+}
+
+func TestDecodeRow(t *testing.T) {
+	ports, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUUID: "uuid-1"}, {GoUUID: "uuid-2"}})
+	options, _ := libovsdb.NewOvsMap(map[string]string{"k": "v"})
+	bandwidth, _ := libovsdb.NewOvsMap(map[string]interface{}{"rate": float64(100)})
+	fields := map[string]interface{}{
+		"name":      "lsp1",
+		"tag":       10,
+		"peer":      libovsdb.OvsSet{},
+		"enabled":   libovsdb.OvsSet{},
+		"addresses": "00:00:00:00:00:01",
+		"ports":     *ports,
+		"bandwidth": *bandwidth,
+		"options":   *options,
+	}
+
+	m := &testModel{Ignored: "kept"}
+	assert.Nil(t, decodeRow("uuid-0", fields, m), "test[%s]", "decode")
+	tag := 10
+	assert.Equal(t, &testModel{
+		UUID:      "uuid-0",
+		Name:      "lsp1",
+		Tag:       &tag,
+		Addresses: []string{"00:00:00:00:00:01"},
+		Ports:     []string{"uuid-1", "uuid-2"},
+		Bandwidth: map[string]int{"rate": 100},
+		Options:   options.GoMap,
+		Ignored:   "kept",
+	}, m, "test[%s]", "decoded model")
+
+	// an unexpected shape is an error, the other fields are decoded
+	fields["name"] = 1
+	fields["tag"] = libovsdb.OvsSet{}
+	m = &testModel{}
+	assert.NotNil(t, decodeRow("uuid-0", fields, m), "test[%s]", "wrong type")
+	assert.Equal(t, "", m.Name, "test[%s]", "wrong field unchanged")
+	assert.Nil(t, m.Tag, "test[%s]", "empty optional column")
+	assert.Equal(t, []string{"uuid-1", "uuid-2"}, m.Ports, "test[%s]", "other fields decoded")
+	assert.NotNil(t, decodeRow("uuid-0", fields, *m), "test[%s]", "not a pointer")
+}
+
+type testSwitchModel struct {
+	UUID        string            `ovsdb:"_uuid"`
+	Name        string            `ovsdb:"name"`
+	OtherConfig map[string]string `ovsdb:"other_config"`
+	ExternalIDs map[string]string `ovsdb:"external_ids,omitempty"`
+}
+
+func TestModels(t *testing.T) {
+	ls := &testSwitchModel{Name: LSW_GENERIC, OtherConfig: map[string]string{"subnet": "10.0.0.0/24"}}
+	cmd, err := ovndbapi.InsertModel(tableLogicalSwitch, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, OVNRow{
+		"name":         LSW_GENERIC,
+		"other_config": libovsdb.OvsMap{GoMap: map[interface{}]interface{}{"subnet": "10.0.0.0/24"}},
+	}, OVNRow(cmd.Operations[0].Row), "test[%s]", "inserted row")
+	if err = ovndbapi.Execute(cmd); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd, err := ovndbapi.LSWDel(LSW_GENERIC)
+		if err == nil {
+			err = ovndbapi.Execute(cmd)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	read := &testSwitchModel{}
+	assert.Nil(t, ovndbapi.GetModel(tableLogicalSwitch, LSW_GENERIC, read), "test[%s]", "GetModel")
+	assert.Equal(t, ls.OtherConfig, read.OtherConfig, "test[%s]", "model read")
+	assert.Equal(t, map[string]string{}, read.ExternalIDs, "test[%s]", "empty map read")
+
+	read.OtherConfig = map[string]string{"exclude_ips": "10.0.0.1"}
+	read.Name = "ignored"
+	cmd, err = ovndbapi.UpdateModel(tableLogicalSwitch, read.UUID, read, "other_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ovndbapi.Execute(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	var switches []*testSwitchModel
+	assert.Nil(t, ovndbapi.GetModels(tableLogicalSwitch, &switches), "test[%s]", "GetModels")
+	found := false
+	for _, s := range switches {
+		if s.UUID == read.UUID {
+			found = true
+			assert.Equal(t, LSW_GENERIC, s.Name, "test[%s]", "column not updated")
+			assert.Equal(t, read.OtherConfig, s.OtherConfig, "test[%s]", "column updated")
+		}
+	}
+	assert.True(t, found, "test[%s]", "model listed")
+
+	_, err = ovndbapi.UpdateModel(tableLogicalSwitch, read.UUID, read, "missing")
+	assert.NotNil(t, err, "test[%s]", "unknown column")
+	_, err = ovndbapi.InsertModel(tableLogicalSwitch, &struct {
+		Name int `ovsdb:"name"`
+	}{1})
+	assert.NotNil(t, err, "test[%s]", "wrong type")
+	var notModels []string
+	assert.NotNil(t, ovndbapi.GetModels(tableLogicalSwitch, &notModels), "test[%s]", "not models")
+}
+
+func TestRowDecodeError(t *testing.T) {
+	odbi := &ovnDBImp{cache: map[string]map[string]libovsdb.Row{
+		tableAddressSet: {
+			"uuid-1": {Fields: map[string]interface{}{"name": "as1", "addresses": "10.0.0.1"}},
+			"uuid-2": {Fields: map[string]interface{}{"name": 2, "addresses": "10.0.0.2"}},
+		},
+	}}
+
+	as, err := odbi.RowToAddressSet("uuid-1")
+	assert.Nil(t, err, "test[%s]", "decoded row")
+	assert.Equal(t, "as1", as.Name, "test[%s]", "decoded row")
+	as, err = odbi.RowToAddressSet("uuid-2")
+	assert.NotNil(t, err, "test[%s]", "row not matching the model")
+	assert.Nil(t, as, "test[%s]", "row not matching the model")
+
+	sets := odbi.GetAddressSets()
+	assert.Equal(t, 1, len(sets), "test[%s]", "row skipped by the getter")
+	assert.Equal(t, "uuid-1", sets[0].UUID, "test[%s]", "row skipped by the getter")
+}
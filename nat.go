@@ -16,12 +16,204 @@
 
 package goovn
 
+import (
+	"fmt"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// NAT types
+const (
+	NATSnat        string = "snat"
+	NATDnat        string = "dnat"
+	NATDnatAndSnat string = "dnat_and_snat"
+)
+
 type NAT struct {
-	UUID        string
-	Type        string
-	ExternalIP  string
-	ExternalMAC string
-	LogicalIP   string
-	LogicalPort string
-	ExternalID  map[interface{}]interface{}
+	UUID        string                      `ovsdb:"_uuid"`
+	Type        string                      `ovsdb:"type"`
+	ExternalIP  string                      `ovsdb:"external_ip"`
+	ExternalMAC string                      `ovsdb:"external_mac"`
+	LogicalIP   string                      `ovsdb:"logical_ip"`
+	LogicalPort string                      `ovsdb:"logical_port"`
+	ExternalID  map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+// natMatches reports whether the cached nat row is selected by ntype and ip,
+// following the ovn-nbctl lr-nat-del semantic: ip is the logical ip for snat
+// and the external ip for dnat and dnat_and_snat.
+func (odbi *ovnDBImp) natMatches(uuid string, ntype string, ip string) bool {
+	nat := odbi.cache[tableNAT][uuid]
+	if ntype != "" && nat.Fields["type"] != ntype {
+		return false
+	}
+	if ip == "" {
+		return true
+	}
+	if nat.Fields["type"] == NATSnat {
+		return nat.Fields["logical_ip"] == ip
+	}
+	return nat.Fields["external_ip"] == ip
+}
+
+func (odbi *ovnDBImp) lrNATAddImp(lr string, ntype string, externalIp string, logicalIp string, logicalPort string, externalMac string, external_ids map[string]string) (*OvnCommand, error) {
+	switch ntype {
+	case NATSnat, NATDnat:
+		if logicalPort != "" || externalMac != "" {
+			return nil, fmt.Errorf("logical_port and external_mac are only supported for %s", NATDnatAndSnat)
+		}
+	case NATDnatAndSnat:
+		if (logicalPort == "") != (externalMac == "") {
+			return nil, fmt.Errorf("both logical_port and external_mac must be given for distributed %s", NATDnatAndSnat)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported nat type %s", ntype)
+	}
+
+	odbi.cachemutex.RLock()
+	_, nats, err := odbi.getRowRefsByName(tableLogicalRouter, lr, "nat")
+	if err == nil {
+		ip := externalIp
+		if ntype == NATSnat {
+			ip = logicalIp
+		}
+		for _, nat := range nats {
+			if odbi.natMatches(nat, ntype, ip) {
+				err = ErrorExist
+				break
+			}
+		}
+	}
+	odbi.cachemutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	namedUUID, err := newRowUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := odbi.encodeRow(tableNAT, &NAT{
+		Type:        ntype,
+		ExternalIP:  externalIp,
+		ExternalMAC: externalMac,
+		LogicalIP:   logicalIp,
+		LogicalPort: logicalPort,
+		ExternalID:  toModelMap(external_ids),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	insertOp := libovsdb.Operation{
+		Op:       opInsert,
+		Table:    tableNAT,
+		Row:      row,
+		UUIDName: namedUUID,
+	}
+
+	mutateUUID := []libovsdb.UUID{{GoUUID: namedUUID}}
+	mutateSet, err := libovsdb.NewOvsSet(mutateUUID)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("nat", opInsert, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", lr)
+
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalRouter,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{insertOp, mutateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+// lrNATDelImp deletes the nat rules of lr selected by ntype and ip. With no
+// ntype all rules of lr are deleted, with no ip all rules of ntype.
+func (odbi *ovnDBImp) lrNATDelImp(lr string, ntype string, ip ...string) (*OvnCommand, error) {
+	if len(ip) > 1 {
+		return nil, fmt.Errorf("at most one ip is supported")
+	}
+	matchIP := ""
+	if len(ip) == 1 {
+		if ntype == "" {
+			return nil, fmt.Errorf("nat type is required when ip is given")
+		}
+		matchIP = ip[0]
+	}
+
+	odbi.cachemutex.RLock()
+	var natUUIDs []libovsdb.UUID
+	lrUUID, nats, err := odbi.getRowRefsByName(tableLogicalRouter, lr, "nat")
+	if err == nil {
+		for _, nat := range nats {
+			if odbi.natMatches(nat, ntype, matchIP) {
+				natUUIDs = append(natUUIDs, libovsdb.UUID{GoUUID: nat})
+			}
+		}
+	}
+	odbi.cachemutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(natUUIDs) == 0 {
+		return nil, ErrorNotFound
+	}
+
+	mutateSet, err := libovsdb.NewOvsSet(natUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("nat", opDelete, mutateSet)
+	mucondition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: lrUUID})
+
+	// simple mutate operation
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalRouter,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{mucondition},
+	}
+	operations := []libovsdb.Operation{mutateOp}
+
+	for _, natUUID := range natUUIDs {
+		condition := libovsdb.NewCondition("_uuid", "==", natUUID)
+		deleteOp := libovsdb.Operation{
+			Op:    opDelete,
+			Table: tableNAT,
+			Where: []interface{}{condition},
+		}
+		operations = append(operations, deleteOp)
+	}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) RowToNAT(uuid string) (*NAT, error) {
+	nat := &NAT{}
+	if err := decodeRow(uuid, odbi.cache[tableNAT][uuid].Fields, nat); err != nil {
+		return nil, err
+	}
+	return nat, nil
+}
+
+// Get all nat rules by lr
+func (odbi *ovnDBImp) GetLRNATs(lr string) ([]*NAT, error) {
+	var natlist = []*NAT{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	_, nats, err := odbi.getRowRefsByName(tableLogicalRouter, lr, "nat")
+	if err != nil {
+		return nil, err
+	}
+	for _, nat := range nats {
+		if _, ok := odbi.cache[tableNAT][nat]; ok {
+			if n, err := odbi.RowToNAT(nat); err == nil {
+				natlist = append(natlist, n)
+			}
+		}
+	}
+	return natlist, nil
 }
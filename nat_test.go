@@ -0,0 +1,102 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogicalRouterNAT(t *testing.T) {
+	var cmd *OvnCommand
+	var err error
+
+	cmd, err = ovndbapi.LRAdd(LR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.LRNATAdd(LR, "snat", "10.0.0.1", "192.168.0.0/24", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.LRNATAdd(LR, "dnat_and_snat", "10.0.0.2", "192.168.0.2", LSP, "00:00:00:01:02:03", map[string]string{"A": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.LRNATAdd(LR, "snat", "10.0.0.3", "192.168.0.0/24", "", "", nil)
+	assert.Equal(t, ErrorExist, err, "test[%s]", "add same snat twice")
+
+	_, err = ovndbapi.LRNATAdd(LR, "snat", "10.0.0.3", "192.168.1.0/24", LSP, "00:00:00:01:02:03", nil)
+	assert.Equal(t, true, err != nil, "test[%s]", "logical_port on snat rejected")
+
+	_, err = ovndbapi.LRNATAdd(LR, "dnat_and_snat", "10.0.0.4", "192.168.0.4", LSP, "", nil)
+	assert.Equal(t, true, err != nil, "test[%s]", "logical_port without external_mac rejected")
+
+	nats, err := ovndbapi.GetLRNATs(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(nats) == 2, "test[%s]: %v", "added nat rules", nats)
+	for _, nat := range nats {
+		if nat.Type == "dnat_and_snat" {
+			assert.Equal(t, true, nat.ExternalIP == "10.0.0.2" && nat.LogicalPort == LSP &&
+				nat.ExternalMAC == "00:00:00:01:02:03", "test[%s]: %v", "distributed nat", nat)
+		}
+	}
+
+	lrs := ovndbapi.GetLogicalRouters()
+	assert.Equal(t, true, len(lrs) == 1 && len(lrs[0].NAT) == 2, "test[%s]: %v", "router nat column", lrs)
+
+	cmd, err = ovndbapi.LRNATDel(LR, "snat", "192.168.0.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nats, err = ovndbapi.GetLRNATs(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(nats) == 1 && nats[0].Type == "dnat_and_snat", "test[%s]: %v", "snat removed", nats)
+
+	cmd, err = ovndbapi.LRNATDel(LR, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nats, err = ovndbapi.GetLRNATs(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(nats) == 0, "test[%s]: %v", "all nat removed", nats)
+
+	cmd, err = ovndbapi.LRDel(LR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,35 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+// NBGlobal is the single row of the NB_Global table
+type NBGlobal struct {
+	UUID       string                      `ovsdb:"_uuid"`
+	NbCfg      int                         `ovsdb:"nb_cfg"`
+	SbCfg      int                         `ovsdb:"sb_cfg"`
+	HvCfg      int                         `ovsdb:"hv_cfg"`
+	Options    map[interface{}]interface{} `ovsdb:"options"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+func (odbi *ovnDBImp) RowToNBGlobal(uuid string) (*NBGlobal, error) {
+	global := &NBGlobal{}
+	if err := decodeRow(uuid, odbi.cache[tableNBGlobal][uuid].Fields, global); err != nil {
+		return nil, err
+	}
+	return global, nil
+}
@@ -0,0 +1,53 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+func TestCommandResults(t *testing.T) {
+	cmd := &OvnCommand{Operations: []libovsdb.Operation{
+		{Op: opInsert, Table: tableMeterBand, UUIDName: "rowband"},
+		{Op: opInsert, Table: tableMeter, UUIDName: "rowmeter"},
+		{Op: opSelect, Table: tableMeter},
+		{Op: opMutate, Table: tableMeter},
+	}}
+
+	_, err := cmd.UUID()
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "no uuid before execute")
+
+	cmd.setResults([]libovsdb.OperationResult{
+		{UUID: libovsdb.UUID{GoUUID: "band-uuid"}},
+		{UUID: libovsdb.UUID{GoUUID: "meter-uuid"}},
+		{Rows: []libovsdb.ResultRow{{"name": "m1"}, {"name": "m2"}}},
+		{Count: 1},
+	})
+
+	uuid, err := cmd.UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "meter-uuid", uuid, "test[%s]", "last inserted row")
+	assert.Equal(t, map[string]string{"rowband": "band-uuid", "rowmeter": "meter-uuid"}, cmd.UUIDs(), "test[%s]", "named uuids mapped")
+	assert.Equal(t, 2, len(cmd.Results[2]), "test[%s]", "selected rows")
+	assert.Equal(t, 0, len(cmd.Results[3]), "test[%s]", "no rows for mutate")
+}
+
+func TestCommandNamedUUID(t *testing.T) {
+	cmd := &OvnCommand{Operations: []libovsdb.Operation{
+		{Op: opInsert, Table: tableMeterBand, UUIDName: "rowband"},
+		{Op: opInsert, Table: tableMeter, UUIDName: "rowmeter"},
+		{Op: opMutate, Table: tableMeter},
+	}}
+	named, err := cmd.NamedUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "rowmeter", named, "test[%s]", "named uuid of the main row")
+
+	cmd = &OvnCommand{Operations: []libovsdb.Operation{{Op: opDelete, Table: tableMeter}}}
+	_, err = cmd.NamedUUID()
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "nothing inserted")
+}
@@ -17,8 +17,11 @@
 package goovn
 
 import (
-	"fmt"
+	"context"
+	"crypto/tls"
+	"net"
 	"sync"
+	"time"
 
 	"github.com/unistack-org/libovsdb"
 )
@@ -33,6 +36,7 @@ const (
 
 const (
 	NBDB string = "OVN_Northbound"
+	SBDB string = "OVN_Southbound"
 )
 const (
 	tableNBGlobal                 string = "NB_Global"
@@ -56,6 +60,14 @@ const (
 	tableGatewayChassis           string = "Gateway_Chassis"
 )
 
+const (
+	tableChassis         string = "Chassis"
+	tableEncap           string = "Encap"
+	tablePortBinding     string = "Port_Binding"
+	tableDatapathBinding string = "Datapath_Binding"
+	tableMACBinding      string = "MAC_Binding"
+)
+
 // OVN supporter protocols
 const (
 	UNIX string = "unix"
@@ -64,51 +76,173 @@ const (
 )
 
 type ovnDBClient struct {
-	socket   string
-	server   string
-	port     int
-	protocol string
-	dbclient *libovsdb.OvsdbClient
+	remotes []remote
+	// next is the index of the remote tried first on the next connect
+	next     int
+	tls      *tls.Config
+	certFile string
+	keyFile  string
+	caFile   string
+	dbclient *ovsdbConn
 }
 
 type ovnDBImp struct {
 	client     *ovnDBClient
+	db         string
 	cache      map[string]map[string]libovsdb.Row
-	cachemutex sync.Mutex
-	tranmutex  sync.Mutex
+	cachemutex sync.RWMutex
+	// schema of the database on the current connection, under cachemutex
+	schema libovsdb.DatabaseSchema
+	// transem serializes transactions and the switch to a new connection.
+	// It is a one slot semaphore rather than a mutex, so waiting for it is
+	// abandoned when the context of a transaction is done.
+	transem    chan struct{}
 	callback   OVNSignal
+	sbcallback OVNSBSignal
+	// tables kept in the cache, nil for the whole database
+	tables []string
+	// stop is closed by close to end reconnecting
+	stop        chan struct{}
+	noReconnect bool
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	statecb     func(state ConnState)
+	// eventmutex keeps the changes of concurrent updates in order
+	eventmutex      sync.Mutex
+	submutex        sync.Mutex
+	subscriptions   map[*subscription]struct{}
+	eventBufferSize int
 }
 
 type OVNDB struct {
 	imp *ovnDBImp
 }
 
-var once sync.Once
+// Config is the configuration of a client connection to an ovn database
+type Config struct {
+	// Protocol is one of UNIX, TCP and SSL
+	Protocol string
+	// Socket is the path of the unix socket, used with UNIX
+	Socket string
+	// Server and Port address the database, used with TCP and SSL
+	Server string
+	Port   int
+	// Remotes is a comma separated list of remotes in the ovsdb format, as
+	// "tcp:10.0.0.1:6641,ssl:10.0.0.2:6641", and takes precedence over the
+	// fields above. Members of a clustered database are only used while they
+	// are the leader, the client fails over to the next one otherwise.
+	Remotes string
+	// TLSConfig is used to dial ssl remotes. An empty ServerName is set to
+	// the host of the remote, so its certificate is verified against it.
+	// Certificates are only presented when a connection is established, an
+	// open connection is not renegotiated when they change.
+	TLSConfig *tls.Config
+	// CertFile, KeyFile and CAFile are PEM files used for ssl remotes when
+	// TLSConfig is not set. They are read at every connection, so rotated
+	// certificates are used from the next reconnection on: they are not
+	// reloaded while the connection stays up. Without any of them the
+	// deprecated CLIENT_CERT_CA_CERT and CLIENT_PRIVKEY environment variables
+	// are used.
+	CertFile string
+	KeyFile  string
+	CAFile   string
+	// SignalCB is notified of northbound changes, used by NewClient
+	SignalCB OVNSignal
+	// SBSignalCB is notified of southbound changes, used by NewSBClient
+	SBSignalCB OVNSBSignal
+	// ConnStateCB is called whenever the connection is lost, restored or
+	// closed
+	ConnStateCB func(state ConnState)
+	// DisableReconnect leaves the client disconnected when the connection
+	// is lost, by default it is dialed again with an exponential backoff
+	// between ReconnectMinBackoff (1s if unset) and ReconnectMaxBackoff
+	// (30s if unset).
+	DisableReconnect    bool
+	ReconnectMinBackoff time.Duration
+	ReconnectMaxBackoff time.Duration
+	// EventBufferSize is the number of events buffered for a subscription,
+	// 1024 if unset. See Subscribe for what happens when it is full.
+	EventBufferSize int
+}
+
+// NewClient opens a connection to the northbound database. Every client
+// has its own connection, cache and callback and must be closed by Close.
+func NewClient(cfg Config) (OVNDBApi, error) {
+	return NewClientContext(context.Background(), cfg)
+}
+
+// NewClientContext is NewClient with ctx bounding the connection and the
+// monitor setup, a *TimeoutError is returned when it is done first.
+func NewClientContext(ctx context.Context, cfg Config) (OVNDBApi, error) {
+	odb, err := newOVNDBClient(ctx, cfg, NBDB)
+	if err != nil {
+		return nil, err
+	}
+
+	imp, err := newNBImp(ctx, odb, cfg)
+	if err != nil {
+		odb.dbclient.Disconnect()
+		return nil, err
+	}
+
+	return &OVNDB{imp}, nil
+}
+
+// NewClientConn is NewClientContext on an established connection to the
+// northbound database, as one to an ovsdbtest.Server. The connection
+// fields of cfg are ignored and there is nothing to reconnect to, the
+// client stays disconnected once conn fails.
+func NewClientConn(ctx context.Context, conn net.Conn, cfg Config) (OVNDBApi, error) {
+	dbclient, err := newOVSDBConn(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DisableReconnect = true
+	imp, err := newNBImp(ctx, &ovnDBClient{dbclient: dbclient}, cfg)
+	if err != nil {
+		dbclient.Disconnect()
+		return nil, err
+	}
+	return &OVNDB{imp}, nil
+}
+
+var ovnDBApiMutex sync.Mutex
 var ovnDBApi OVNDBApi
 
+// GetInstance returns the process wide northbound client, creating it on
+// first use. A failed connect is retried by the next call.
+//
+// Deprecated: use NewClient, which allows independent clients.
 func GetInstance(socketfile string, proto string, server string, port int, callback OVNSignal) (OVNDBApi, error) {
-	var err error
-
-	once.Do(func() {
-		var dbapi *OVNDB
-
-		switch proto {
-		case UNIX:
-			dbapi, err = newNBBySocket(socketfile, callback)
-		case TCP:
-			dbapi, err = newNBByServer(server, port, callback, TCP)
-		case SSL:
-			dbapi, err = newNBByServer(server, port, callback, SSL)
-		default:
-			err = fmt.Errorf("the protocol [%s] is not supported", proto)
-		}
-		if err != nil {
-			return
-		}
-		ovnDBApi = dbapi
+	ovnDBApiMutex.Lock()
+	defer ovnDBApiMutex.Unlock()
+
+	if ovnDBApi != nil {
+		return ovnDBApi, nil
+	}
+
+	dbapi, err := NewClient(Config{
+		Protocol: proto,
+		Socket:   socketfile,
+		Server:   server,
+		Port:     port,
+		SignalCB: callback,
 	})
+	if err != nil {
+		return nil, err
+	}
+	ovnDBApi = dbapi
+	return ovnDBApi, nil
+}
 
-	return ovnDBApi, err
+// releaseInstance forgets the process wide client once it is closed, so
+// GetInstance connects again.
+func releaseInstance(c OVNDBApi) {
+	ovnDBApiMutex.Lock()
+	defer ovnDBApiMutex.Unlock()
+	if ovnDBApi == c {
+		ovnDBApi = nil
+	}
 }
 
 func SetCallBack(c OVNDBApi, callback OVNSignal) {
@@ -17,76 +17,111 @@
 package goovn
 
 import (
-	"errors"
-
-	"github.com/unistack-org/libovsdb"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
 )
 
-func newNBClient(socketfile string, proto string, server string, port int) (*ovnDBClient, error) {
+func newOVNDBClient(ctx context.Context, cfg Config, db string) (*ovnDBClient, error) {
+	remotes, err := configRemotes(cfg)
+	if err != nil {
+		return nil, err
+	}
 	client := &ovnDBClient{
-		socket:   socketfile,
-		server:   server,
-		port:     port,
-		protocol: UNIX,
+		remotes:  remotes,
+		tls:      cfg.TLSConfig,
+		certFile: cfg.CertFile,
+		keyFile:  cfg.KeyFile,
+		caFile:   cfg.CAFile,
 	}
 
-	switch proto {
-	case UNIX:
-		clt, err := libovsdb.ConnectWithUnixSocket(socketfile)
-		if err != nil {
-			return nil, err
+	clt, err := client.connect(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	client.dbclient = clt
+	return client, nil
+}
+
+// connect dials the remotes in turn, starting with the last one used, and
+// returns the first connection usable for db. It is used for the first
+// connection as well as for every reconnection, which moves on to the next
+// remote only when the last one used fails or is no longer the leader.
+func (client *ovnDBClient) connect(ctx context.Context, db string) (*ovsdbConn, error) {
+	var errs []string
+	for i := range client.remotes {
+		idx := (client.next + i) % len(client.remotes)
+		r := client.remotes[idx]
+		clt, err := client.dial(ctx, r)
+		if err == nil {
+			err = checkLeader(ctx, clt, db)
+			if err != nil {
+				clt.Disconnect()
+			}
 		}
-		client.dbclient = clt
-		return client, nil
-	case TCP:
-		clt, err := libovsdb.Connect(server, port, proto)
-
 		if err != nil {
-			return nil, err
+			if ctx.Err() != nil {
+				return nil, &TimeoutError{Method: "connect", Err: ctx.Err()}
+			}
+			errs = append(errs, fmt.Sprintf("%s: %v", r, err))
+			continue
 		}
-		client.dbclient = clt
-		return client, nil
-	case SSL:
-		// for connection using SSL, make sure to set CLIENT_CERT_CA_CERT
-		// and CLIENT_PRIVKEY in the env variable. CLIENT_CERT_CA_CERT is a
-		// combination of client cert and ca cert appended in the same file.
-		clt, err := libovsdb.Connect(server, port, proto)
+		client.next = idx
+		return clt, nil
+	}
+	return nil, fmt.Errorf("failed to connect to %s: %s", db, strings.Join(errs, "; "))
+}
 
+func (client *ovnDBClient) dial(ctx context.Context, r remote) (*ovsdbConn, error) {
+	var dialer net.Dialer
+	var conn net.Conn
+	var err error
+	switch r.protocol {
+	case UNIX:
+		conn, err = dialer.DialContext(ctx, UNIX, r.address)
+	case TCP:
+		conn, err = dialer.DialContext(ctx, TCP, r.target())
+	case SSL:
+		var config *tls.Config
+		config, err = client.tlsConfig(r.host())
 		if err != nil {
 			return nil, err
 		}
-		client.dbclient = clt
-		return client, nil
-	}
-	return nil, errors.New("OVN DB initial failed: (unsupported protocol)")
-}
-
-func newNBBySocket(socketfile string, callback OVNSignal) (*OVNDB, error) {
-	odb, err := newNBClient(socketfile, UNIX, "", 0)
-	if err != nil {
-		return nil, err
-	}
-
-	imp, err := newNBImp(odb, callback)
-	if err != nil {
-		return nil, err
+		conn, err = dialer.DialContext(ctx, TCP, r.target())
+		if err == nil {
+			conn, err = tlsHandshake(ctx, conn, config)
+		}
+	default:
+		return nil, fmt.Errorf("the protocol [%s] is not supported", r.protocol)
 	}
-
-	return &OVNDB{imp}, nil
-}
-
-func newNBByServer(server string, port int, callback OVNSignal, protocol string) (*OVNDB, error) {
-	odb, err := newNBClient("", protocol, server, port)
 	if err != nil {
 		return nil, err
 	}
-
-	imp, err := newNBImp(odb, callback)
-	if err != nil {
-		return nil, err
+	return newOVSDBConn(ctx, conn)
+}
+
+// tlsHandshake runs the handshake over conn, closing it to abort the
+// handshake when ctx is done.
+func tlsHandshake(ctx context.Context, conn net.Conn, config *tls.Config) (net.Conn, error) {
+	tlsconn := tls.Client(conn, config)
+	errch := make(chan error, 1)
+	go func() {
+		errch <- tlsconn.Handshake()
+	}()
+	select {
+	case err := <-errch:
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsconn, nil
+	case <-ctx.Done():
+		conn.Close()
+		<-errch
+		return nil, ctx.Err()
 	}
-
-	return &OVNDB{imp}, nil
 }
 
 func (odb *OVNDB) LSWAdd(lsw string) (*OvnCommand, error) {
@@ -133,6 +168,34 @@ func (odb *OVNDB) LRPDel(lr string, lrp string) (*OvnCommand, error) {
 	return odb.imp.lrpDelImp(lr, lrp)
 }
 
+func (odb *OVNDB) LRNATAdd(lr string, ntype string, externalIp string, logicalIp string, logicalPort string, externalMac string, external_ids map[string]string) (*OvnCommand, error) {
+	return odb.imp.lrNATAddImp(lr, ntype, externalIp, logicalIp, logicalPort, externalMac, external_ids)
+}
+
+func (odb *OVNDB) LRNATDel(lr string, ntype string, ip ...string) (*OvnCommand, error) {
+	return odb.imp.lrNATDelImp(lr, ntype, ip...)
+}
+
+func (odb *OVNDB) LRSRAdd(lr string, ip_prefix string, nexthop string, output_port string, policy string, external_ids map[string]string) (*OvnCommand, error) {
+	return odb.imp.lrsrAddImp(lr, ip_prefix, nexthop, output_port, policy, external_ids)
+}
+
+func (odb *OVNDB) LRSRDel(lr string, ip_prefix string, nexthop string) (*OvnCommand, error) {
+	return odb.imp.lrsrDelImp(lr, ip_prefix, nexthop)
+}
+
+func (odb *OVNDB) LRPSetGatewayChassis(lrp string, chassis string, priority int) (*OvnCommand, error) {
+	return odb.imp.lrpSetGatewayChassisImp(lrp, chassis, priority)
+}
+
+func (odb *OVNDB) LRPDelGatewayChassis(lrp string, chassis string) (*OvnCommand, error) {
+	return odb.imp.lrpDelGatewayChassisImp(lrp, chassis)
+}
+
+func (odb *OVNDB) LRPRebalanceGatewayChassis(lrp string, chassis []string) (*OvnCommand, error) {
+	return odb.imp.lrpRebalanceGatewayChassisImp(lrp, chassis)
+}
+
 func (odb *OVNDB) LBAdd(name string, vipPort string, protocol string, addrs []string) (*OvnCommand, error) {
 	return odb.imp.lbAddImp(name, vipPort, protocol, addrs)
 }
@@ -146,11 +209,55 @@ func (odb *OVNDB) LBDel(name string) (*OvnCommand, error) {
 }
 
 func (odb *OVNDB) ACLAdd(lsw, direct, match, action string, priority int, external_ids map[string]string, logflag bool, meter string) (*OvnCommand, error) {
-	return odb.imp.aclAddImp(lsw, direct, match, action, priority, external_ids, logflag, meter)
+	return odb.imp.aclAddImp(tableLogicalSwitch, lsw, direct, match, action, priority, external_ids, logflag, meter)
 }
 
 func (odb *OVNDB) ACLDel(lsw, direct, match string, priority int, external_ids map[string]string) (*OvnCommand, error) {
-	return odb.imp.aclDelImp(lsw, direct, match, priority, external_ids)
+	return odb.imp.aclDelImp(tableLogicalSwitch, lsw, direct, match, priority, external_ids)
+}
+
+func (odb *OVNDB) PGACLAdd(group, direct, match, action string, priority int, external_ids map[string]string, logflag bool, meter string) (*OvnCommand, error) {
+	return odb.imp.aclAddImp(tablePortGroup, group, direct, match, action, priority, external_ids, logflag, meter)
+}
+
+func (odb *OVNDB) PGACLDel(group, direct, match string, priority int, external_ids map[string]string) (*OvnCommand, error) {
+	return odb.imp.aclDelImp(tablePortGroup, group, direct, match, priority, external_ids)
+}
+
+func (odb *OVNDB) QoSAdd(lsw string, direction string, priority int, match string, action map[string]int, bandwidth map[string]int, external_ids map[string]string) (*OvnCommand, error) {
+	return odb.imp.qosAddImp(lsw, direction, priority, match, action, bandwidth, external_ids)
+}
+
+func (odb *OVNDB) QoSDel(lsw string, direction string, priority int, match string) (*OvnCommand, error) {
+	return odb.imp.qosDelImp(lsw, direction, priority, match)
+}
+
+func (odb *OVNDB) MeterAdd(name string, unit string, bands []*MeterBand, external_ids map[string]string) (*OvnCommand, error) {
+	return odb.imp.meterAddImp(name, unit, bands, external_ids)
+}
+
+func (odb *OVNDB) MeterDel(name string) (*OvnCommand, error) {
+	return odb.imp.meterDelImp(name)
+}
+
+func (odb *OVNDB) DNSAdd(lsw string, records map[string]string, external_ids map[string]string) (*OvnCommand, error) {
+	return odb.imp.dnsAddImp(lsw, records, external_ids)
+}
+
+func (odb *OVNDB) DNSDel(uuid string) (*OvnCommand, error) {
+	return odb.imp.dnsDelImp(uuid)
+}
+
+func (odb *OVNDB) DNSSetRecords(uuid string, records map[string]string) (*OvnCommand, error) {
+	return odb.imp.dnsSetRecordsImp(uuid, records)
+}
+
+func (odb *OVNDB) LSWAttachDNS(lsw string, uuid string) (*OvnCommand, error) {
+	return odb.imp.lswAttachDNSImp(lsw, uuid)
+}
+
+func (odb *OVNDB) LSWDetachDNS(lsw string, uuid string) (*OvnCommand, error) {
+	return odb.imp.lswDetachDNSImp(lsw, uuid)
 }
 
 func (odb *OVNDB) ASAdd(name string, addrs []string, external_ids map[string]string) (*OvnCommand, error) {
@@ -185,10 +292,58 @@ func (odb *OVNDB) LSPSetOpt(lsp string, options map[string]string) (*OvnCommand,
 	return odb.imp.LSPSetOpt(lsp, options)
 }
 
+func (odb *OVNDB) List(table string) (*OvnCommand, error) {
+	return odb.imp.listImp(table)
+}
+
+func (odb *OVNDB) Find(table string, conditions ...Condition) (*OvnCommand, error) {
+	return odb.imp.findImp(table, conditions...)
+}
+
+func (odb *OVNDB) Get(table, record, column string) (*OvnCommand, error) {
+	return odb.imp.getImp(table, record, column)
+}
+
+func (odb *OVNDB) Set(table, record string, values map[string]interface{}) (*OvnCommand, error) {
+	return odb.imp.setImp(table, record, values)
+}
+
+func (odb *OVNDB) Add(table, record, column string, values interface{}) (*OvnCommand, error) {
+	return odb.imp.addImp(table, record, column, values)
+}
+
+func (odb *OVNDB) Remove(table, record, column string, values interface{}) (*OvnCommand, error) {
+	return odb.imp.removeImp(table, record, column, values)
+}
+
+func (odb *OVNDB) Clear(table, record string, columns ...string) (*OvnCommand, error) {
+	return odb.imp.clearImp(table, record, columns...)
+}
+
+func (odb *OVNDB) InsertModel(table string, model interface{}) (*OvnCommand, error) {
+	return odb.imp.insertModelImp(table, model)
+}
+
+func (odb *OVNDB) UpdateModel(table, record string, model interface{}, columns ...string) (*OvnCommand, error) {
+	return odb.imp.updateModelImp(table, record, model, columns...)
+}
+
+func (odb *OVNDB) GetModels(table string, models interface{}) error {
+	return odb.imp.GetModels(table, models)
+}
+
+func (odb *OVNDB) GetModel(table, record string, model interface{}) error {
+	return odb.imp.GetModel(table, record, model)
+}
+
 func (odb *OVNDB) Execute(cmds ...*OvnCommand) error {
 	return odb.imp.Execute(cmds...)
 }
 
+func (odb *OVNDB) ExecuteContext(ctx context.Context, cmds ...*OvnCommand) error {
+	return odb.imp.ExecuteContext(ctx, cmds...)
+}
+
 func (odb *OVNDB) GetLogicSwitches() []*LogicalSwitch {
 	return odb.imp.GetLogicSwitches()
 }
@@ -201,10 +356,42 @@ func (odb *OVNDB) GetLogicalRouterPortsByRouter(lr string) ([]*LogicalRouterPort
 	return odb.imp.GetLogicalRouterPortsByRouter(lr)
 }
 
+func (odb *OVNDB) GetLRNATs(lr string) ([]*NAT, error) {
+	return odb.imp.GetLRNATs(lr)
+}
+
+func (odb *OVNDB) GetLogicalRouterStaticRoutes(lr string) ([]*LogicalRouterStaticRoute, error) {
+	return odb.imp.GetLogicalRouterStaticRoutes(lr)
+}
+
+func (odb *OVNDB) GetGatewayChassis(lrp string) ([]*GatewayChassis, error) {
+	return odb.imp.GetGatewayChassis(lrp)
+}
+
 func (odb *OVNDB) GetACLsBySwitch(lsw string) []*ACL {
 	return odb.imp.GetACLsBySwitch(lsw)
 }
 
+func (odb *OVNDB) GetACLsByPortGroup(group string) []*ACL {
+	return odb.imp.GetACLsByPortGroup(group)
+}
+
+func (odb *OVNDB) GetQoSBySwitch(lsw string) ([]*QoS, error) {
+	return odb.imp.GetQoSBySwitch(lsw)
+}
+
+func (odb *OVNDB) GetMeters() []*Meter {
+	return odb.imp.GetMeters()
+}
+
+func (odb *OVNDB) GetDNS(uuid string) (*DNS, error) {
+	return odb.imp.GetDNS(uuid)
+}
+
+func (odb *OVNDB) GetDNSBySwitch(lsw string) ([]*DNS, error) {
+	return odb.imp.GetDNSBySwitch(lsw)
+}
+
 func (odb *OVNDB) GetAddressSets() []*AddressSet {
 	return odb.imp.GetAddressSets()
 }
@@ -237,6 +424,47 @@ func (odb *OVNDB) GetDHCPOptions() []*DHCPOptions {
 	return odb.imp.getDHCPOptionsImp()
 }
 
+func (odb *OVNDB) PGAdd(group string, ports []string, external_ids map[string]string) (*OvnCommand, error) {
+	return odb.imp.pgAddImp(group, ports, external_ids)
+}
+
+func (odb *OVNDB) PGUpdate(group string, ports []string, external_ids map[string]string) (*OvnCommand, error) {
+	return odb.imp.pgUpdateImp(group, ports, external_ids)
+}
+
+func (odb *OVNDB) PGDel(group string) (*OvnCommand, error) {
+	return odb.imp.pgDelImp(group)
+}
+
+func (odb *OVNDB) PGAddPort(group string, port string) (*OvnCommand, error) {
+	return odb.imp.pgAddPortImp(group, port)
+}
+
+func (odb *OVNDB) PGRemovePort(group string, port string) (*OvnCommand, error) {
+	return odb.imp.pgRemovePortImp(group, port)
+}
+
+func (odb *OVNDB) GetPortGroups() []*PortGroup {
+	return odb.imp.GetPortGroups()
+}
+
+func (odb *OVNDB) GetPortGroupByName(group string) (*PortGroup, error) {
+	return odb.imp.GetPortGroupByName(group)
+}
+
 func (odb *OVNDB) SetCallBack(callback OVNSignal) {
 	odb.imp.callback = callback
 }
+
+func (odb *OVNDB) Subscribe(filter EventFilter) (<-chan Event, func()) {
+	return odb.imp.Subscribe(filter)
+}
+
+func (odb *OVNDB) SchemaVersion() string {
+	return odb.imp.SchemaVersion()
+}
+
+func (odb *OVNDB) Close() error {
+	releaseInstance(odb)
+	return odb.imp.close()
+}
@@ -17,6 +17,7 @@
 package goovn
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -32,25 +33,110 @@ var (
 
 type OVNRow map[string]interface{}
 
-func newNBImp(client *ovnDBClient, callback OVNSignal) (*ovnDBImp, error) {
-	nbimp := &ovnDBImp{
-		client: client,
-		cache:  make(map[string]map[string]libovsdb.Row),
-	}
-	initial, err := nbimp.client.dbclient.MonitorAll(NBDB, "")
+func newNBImp(ctx context.Context, client *ovnDBClient, cfg Config) (*ovnDBImp, error) {
+	nbimp := newOVNDBImp(client, NBDB, nil, cfg)
+	err := nbimp.start(ctx)
 	if err != nil {
 		return nil, err
 	}
-	nbimp.populateCache(*initial)
-	notifier := ovnNotifier{nbimp}
-	nbimp.client.dbclient.Register(notifier)
-	nbimp.callback = callback
+	nbimp.callback = cfg.SignalCB
 	return nbimp, nil
 }
 
+// newOVNDBImp returns the implementation for db, caching the given tables or
+// the whole database when tables is nil.
+func newOVNDBImp(client *ovnDBClient, db string, tables []string, cfg Config) *ovnDBImp {
+	odbi := &ovnDBImp{
+		client:      client,
+		db:          db,
+		tables:      tables,
+		cache:       make(map[string]map[string]libovsdb.Row),
+		transem:     make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		noReconnect: cfg.DisableReconnect,
+		minBackoff:  cfg.ReconnectMinBackoff,
+		maxBackoff:  cfg.ReconnectMaxBackoff,
+		statecb:     cfg.ConnStateCB,
+
+		eventBufferSize: cfg.EventBufferSize,
+	}
+	if odbi.eventBufferSize <= 0 {
+		odbi.eventBufferSize = defaultEventBufferSize
+	}
+	if odbi.minBackoff <= 0 {
+		odbi.minBackoff = defaultReconnectMinBackoff
+	}
+	if odbi.maxBackoff <= 0 {
+		odbi.maxBackoff = defaultReconnectMaxBackoff
+	}
+	if odbi.maxBackoff < odbi.minBackoff {
+		odbi.maxBackoff = odbi.minBackoff
+	}
+	return odbi
+}
+
+// start fills the cache from the initial monitor reply and then follows the
+// updates of the connection.
+func (odbi *ovnDBImp) start(ctx context.Context) error {
+	conn := odbi.client.dbclient
+	odbi.setSchema(conn)
+	notifier := newOVNNotifier(odbi, conn)
+	conn.Register(notifier)
+	initial, err := odbi.monitor(ctx)
+	if err == nil {
+		err = odbi.monitorServer(ctx, conn)
+	}
+	if err != nil {
+		// the client is not returned, nothing is left to reconnect
+		close(odbi.stop)
+		return err
+	}
+	notifier.applySnapshot(*initial)
+	return nil
+}
+
+// monitor issues the monitor request of the cached tables on the current
+// connection. The monitor id is the database name.
+func (odbi *ovnDBImp) monitor(ctx context.Context) (*libovsdb.TableUpdates, error) {
+	if odbi.tables == nil {
+		return odbi.client.dbclient.MonitorAll(ctx, odbi.db, odbi.db)
+	}
+	return odbi.monitorTables(ctx, odbi.tables...)
+}
+
+// monitorTables monitors all columns of the given tables only, for databases
+// where monitoring everything is too expensive.
+func (odbi *ovnDBImp) monitorTables(ctx context.Context, tables ...string) (*libovsdb.TableUpdates, error) {
+	schema, ok := odbi.client.dbclient.Schema(odbi.db)
+	if !ok {
+		return nil, fmt.Errorf("schema of database %s not found", odbi.db)
+	}
+
+	requests := make(map[string]libovsdb.MonitorRequest)
+	for _, table := range tables {
+		tableSchema, ok := schema.Tables[table]
+		if !ok {
+			return nil, fmt.Errorf("table %s not found in database %s", table, odbi.db)
+		}
+		var columns []string
+		for column := range tableSchema.Columns {
+			columns = append(columns, column)
+		}
+		requests[table] = libovsdb.MonitorRequest{
+			Columns: columns,
+			Select: libovsdb.MonitorSelect{
+				Initial: true,
+				Insert:  true,
+				Delete:  true,
+				Modify:  true,
+			}}
+	}
+	return odbi.client.dbclient.Monitor(ctx, odbi.db, odbi.db, requests)
+}
+
 func (odbi *ovnDBImp) getRowUUID(table string, row OVNRow) string {
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 	for uuid, drows := range odbi.cache[table] {
 		found := false
 		for field, value := range row {
@@ -88,8 +174,8 @@ func (odbi *ovnDBImp) oMapContians(s, t map[interface{}]interface{}) bool {
 }
 
 func (odbi *ovnDBImp) getRowUUIDContainsUUID(table, field, uuid string) (string, error) {
-	odbi.cachemutex.Lock()
-	defer odbi.cachemutex.Unlock()
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
 	for id, drows := range odbi.cache[table] {
 		v := fmt.Sprintf("%s", drows.Fields[field])
 		if strings.Contains(v, uuid) {
@@ -99,28 +185,71 @@ func (odbi *ovnDBImp) getRowUUIDContainsUUID(table, field, uuid string) (string,
 	return "", ErrorNotFound
 }
 
-func (odbi *ovnDBImp) transact(ops ...libovsdb.Operation) ([]libovsdb.OperationResult, error) {
+// getRowRefsByName returns the uuid of the row named name in table and the
+// uuids referenced from its column. Caller must hold cachemutex.
+func (odbi *ovnDBImp) getRowRefsByName(table, name, column string) (string, []string, error) {
+	for uuid, drows := range odbi.cache[table] {
+		if rname, ok := drows.Fields["name"].(string); ok && rname == name {
+			return uuid, odbi.getRefUUIDs(drows.Fields[column]), nil
+		}
+	}
+	return "", nil, ErrorNotFound
+}
+
+// getRefUUIDs returns the uuids held by a reference column value, which is
+// either a single uuid or a set of uuids.
+func (odbi *ovnDBImp) getRefUUIDs(value interface{}) []string {
+	switch refs := value.(type) {
+	case libovsdb.UUID:
+		return []string{refs.GoUUID}
+	case libovsdb.OvsSet:
+		return odbi.ConvertGoSetToUUIDArray(refs)
+	}
+	return []string{}
+}
+
+// lockTransact waits for the running transaction or connection switch to be
+// done, see transem
+func (odbi *ovnDBImp) lockTransact() {
+	odbi.transem <- struct{}{}
+}
+
+func (odbi *ovnDBImp) unlockTransact() {
+	<-odbi.transem
+}
+
+func (odbi *ovnDBImp) transact(ctx context.Context, ops ...libovsdb.Operation) ([]libovsdb.OperationResult, error) {
 	// Only support one trans at same time now.
-	odbi.tranmutex.Lock()
-	defer odbi.tranmutex.Unlock()
-	reply, err := odbi.client.dbclient.Transact(NBDB, ops...)
+	select {
+	case odbi.transem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, &TimeoutError{Method: "transact", Err: ctx.Err()}
+	}
+	defer odbi.unlockTransact()
+	reply, err := odbi.client.dbclient.Transact(ctx, odbi.db, ops...)
 
 	if err != nil {
 		return reply, err
 	}
 
-	if len(reply) < len(ops) {
-		for i, o := range reply {
-			if o.Error != "" && i < len(ops) {
-				return nil, errors.New(fmt.Sprint("Transaction Failed due to an error :", o.Error, " details:", o.Details, " in ", ops[i]))
-			}
+	// the reply has a result per operation, null after a failed one, and
+	// one more when the commit failed
+	for i, o := range reply {
+		if o.Error != "" {
+			return nil, newTransactionError(ops, i, o)
 		}
+	}
+	if len(reply) < len(ops) {
 		return reply, errors.New(fmt.Sprint("Number of Replies should be atleast equal to number of operations"))
 	}
 	return reply, nil
 }
 
 func (odbi *ovnDBImp) Execute(cmds ...*OvnCommand) error {
+	return odbi.ExecuteContext(context.Background(), cmds...)
+}
+
+func (odbi *ovnDBImp) ExecuteContext(ctx context.Context, cmds ...*OvnCommand) error {
 	if cmds == nil {
 		return nil
 	}
@@ -130,10 +259,37 @@ func (odbi *ovnDBImp) Execute(cmds ...*OvnCommand) error {
 			ops = append(ops, cmd.Operations...)
 		}
 	}
-	_, err := odbi.transact(ops...)
+	reply, err := odbi.transact(ctx, ops...)
 	if err != nil {
+		if txerr, ok := err.(*TransactionError); ok && txerr.Operation != nil {
+			txerr.Command = commandOfOperation(cmds, txerr.Index)
+		}
 		return err
 	}
+
+	i := 0
+	for _, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		cmd.setResults(reply[i : i+len(cmd.Operations)])
+		i += len(cmd.Operations)
+	}
+	return nil
+}
+
+// commandOfOperation returns the command holding the operation at index of
+// the transaction made of cmds.
+func commandOfOperation(cmds []*OvnCommand, index int) *OvnCommand {
+	for _, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		if index < len(cmd.Operations) {
+			return cmd
+		}
+		index -= len(cmd.Operations)
+	}
 	return nil
 }
 
@@ -149,9 +305,25 @@ func (odbi *ovnDBImp) float64_to_int(row libovsdb.Row) {
 }
 
 func (odbi *ovnDBImp) populateCache(updates libovsdb.TableUpdates) {
+	odbi.eventmutex.Lock()
+	defer odbi.eventmutex.Unlock()
+	// the changes are dispatched once the cache is unlocked, so callbacks
+	// can use the getters and a slow subscriber cannot hold it
+	changes := odbi.updateCache(updates)
+	for _, change := range changes {
+		odbi.signal(change.event.Table, change.event.UUID, change.event.Old, change.event.New)
+	}
+	odbi.publish(changes)
+}
+
+// updateCache applies updates to the cache and returns the changes made, in
+// order, when there is a callback or a subscription to dispatch them to.
+func (odbi *ovnDBImp) updateCache(updates libovsdb.TableUpdates) []tableChange {
 	empty := libovsdb.Row{}
 	odbi.cachemutex.Lock()
 	defer odbi.cachemutex.Unlock()
+	signaled := odbi.callback != nil || odbi.sbcallback != nil || odbi.subscribed()
+	var changes []tableChange
 	for table, tableUpdate := range updates.Updates {
 		if _, ok := odbi.cache[table]; !ok {
 			odbi.cache[table] = make(map[string]libovsdb.Row)
@@ -161,57 +333,35 @@ func (odbi *ovnDBImp) populateCache(updates libovsdb.TableUpdates) {
 			// missing json number conversion in libovsdb
 			odbi.float64_to_int(row.New)
 
+			// the old row is taken from the cache, row.Old only holds the
+			// modified columns
+			var oldObj, newObj interface{}
+			var oldRow, newRow *libovsdb.Row
+			var oldErr, newErr error
+			if cached, ok := odbi.cache[table][uuid]; ok && signaled {
+				oldRow = &cached
+				oldObj, oldErr = odbi.rowToObject(table, uuid)
+			}
 			if !reflect.DeepEqual(row.New, empty) {
 				odbi.cache[table][uuid] = row.New
-				if odbi.callback != nil {
-					switch table {
-					case tableLogicalRouter:
-						lr := odbi.RowToLogicalRouter(uuid)
-						odbi.callback.OnLogicalRouterCreate(lr)
-					case tableLogicalRouterPort:
-						lrp := odbi.RowToLogicalRouterPort(uuid)
-						odbi.callback.OnLogicalRouterPortCreate(lrp)
-					case tableLogicalSwitch:
-						ls := odbi.RowToLogicalSwitch(uuid)
-						odbi.callback.OnLogicalSwitchCreate(ls)
-					case tableLogicalSwitchPort:
-						lp := odbi.RowToLogicalPort(uuid)
-						odbi.callback.OnLogicalPortCreate(lp)
-					case tableACL:
-						acl := odbi.RowToACL(uuid)
-						odbi.callback.OnACLCreate(acl)
-					case tableDHCPOptions:
-						dhcp := odbi.RowToDHCPOptions(uuid)
-						odbi.callback.OnDHCPOptionsCreate(dhcp)
-					}
+				if signaled {
+					newRow = &row.New
+					newObj, newErr = odbi.rowToObject(table, uuid)
 				}
 			} else {
-				if odbi.callback != nil {
-					switch table {
-					case tableLogicalRouter:
-						lr := odbi.RowToLogicalRouter(uuid)
-						odbi.callback.OnLogicalRouterDelete(lr)
-					case tableLogicalRouterPort:
-						lrp := odbi.RowToLogicalRouterPort(uuid)
-						odbi.callback.OnLogicalRouterPortDelete(lrp)
-					case tableLogicalSwitch:
-						ls := odbi.RowToLogicalSwitch(uuid)
-						odbi.callback.OnLogicalSwitchDelete(ls)
-					case tableLogicalSwitchPort:
-						lp := odbi.RowToLogicalPort(uuid)
-						odbi.callback.OnLogicalPortDelete(lp)
-					case tableACL:
-						acl := odbi.RowToACL(uuid)
-						odbi.callback.OnACLDelete(acl)
-					case tableDHCPOptions:
-						dhcp := odbi.RowToDHCPOptions(uuid)
-						odbi.callback.OnDHCPOptionsDelete(dhcp)
-					}
-				}
 				delete(odbi.cache[table], uuid)
 			}
+			// a change of a row not matching its model is cached but not
+			// dispatched, as the getters skip the row
+			if oldErr != nil || newErr != nil {
+				continue
+			}
+			if oldRow != nil || newRow != nil {
+				changes = append(changes, newTableChange(table, uuid, oldRow, newRow, oldObj, newObj))
+			}
 		}
 	}
+	return changes
 }
 
 func (odbi *ovnDBImp) ConvertGoSetToStringArray(oset libovsdb.OvsSet) []string {
@@ -224,3 +374,14 @@ func (odbi *ovnDBImp) ConvertGoSetToStringArray(oset libovsdb.OvsSet) []string {
 	}
 	return ret
 }
+
+func (odbi *ovnDBImp) ConvertGoSetToUUIDArray(oset libovsdb.OvsSet) []string {
+	var ret = []string{}
+	for _, s := range oset.GoSet {
+		value, ok := s.(libovsdb.UUID)
+		if ok {
+			ret = append(ret, value.GoUUID)
+		}
+	}
+	return ret
+}
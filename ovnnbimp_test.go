@@ -17,12 +17,16 @@
 package goovn
 
 import (
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ebay/go-ovn/ovsdbtest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,43 +44,173 @@ const (
 )
 
 var ovndbapi OVNDBApi
+var ovncfg Config
 
 func TestMain(m *testing.M) {
-	var api OVNDBApi
 	var err error
 
 	var ovs_rundir = os.Getenv("OVS_RUNDIR")
+	var ovn_nb_db = os.Getenv("OVN_NB_DB")
+	if ovs_rundir == "" && ovn_nb_db == "" {
+		// without a database given the tests run against the in-memory
+		// server
+		os.Exit(runWithTestServer(m))
+	}
 	if ovs_rundir == "" {
 		ovs_rundir = OVS_RUNDIR
 	}
-	var ovn_nb_db = os.Getenv("OVN_NB_DB")
 	if ovn_nb_db == "" {
-		var socket = ovs_rundir + "/" + OVNNB_SOCKET
-		api, err = GetInstance(socket, UNIX, "", 0, nil)
-		if err != nil {
-			log.Fatal(err)
-		}
+		ovncfg = Config{Protocol: UNIX, Socket: ovs_rundir + "/" + OVNNB_SOCKET}
 	} else {
 		strs := strings.Split(ovn_nb_db, ":")
 		if len(strs) < 2 || len(strs) > 3 {
 			log.Fatal("Unexpected format of $OVN_NB_DB")
 		}
 		if len(strs) == 2 {
-			var socket = ovs_rundir + "/" + strs[1]
-			api, err = GetInstance(socket, UNIX, "", 0, nil)
-			if err != nil {
-				log.Fatal(err)
-			}
+			ovncfg = Config{Protocol: UNIX, Socket: ovs_rundir + "/" + strs[1]}
 		} else {
 			port, _ := strconv.Atoi(strs[2])
-			api, err = GetInstance("", strs[0], strs[1], port, nil)
+			ovncfg = Config{Protocol: strs[0], Server: strs[1], Port: port}
+		}
+	}
+	if ovncfg.Protocol == UNIX {
+		log.Printf("testing against the ovsdb-server at %s", ovncfg.Socket)
+	} else {
+		log.Printf("testing against the ovsdb-server at %s:%s:%d", ovncfg.Protocol, ovncfg.Server, ovncfg.Port)
+	}
+	ovndbapi, err = NewClient(ovncfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	code := m.Run()
+	ovndbapi.Close()
+	os.Exit(code)
+}
+
+func runWithTestServer(m *testing.M) int {
+	dir, err := ioutil.TempDir("", "goovn")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	server, err := ovsdbtest.NewNBServer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer server.Close()
+	socket := filepath.Join(dir, OVNNB_SOCKET)
+	err = server.ListenUnix(socket)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ovncfg = Config{Protocol: UNIX, Socket: socket}
+	log.Printf("testing against the in-memory database at %s, set OVS_RUNDIR or OVN_NB_DB to use an ovsdb-server", socket)
+	ovndbapi, err = NewClient(ovncfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ovndbapi.Close()
+	return m.Run()
+}
+
+func TestNewClient(t *testing.T) {
+	api, err := NewClient(ovncfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, cancel := ovndbapi.Subscribe(EventFilter{Tables: []string{tableLogicalSwitch}})
+	defer cancel()
+
+	cmd, err := api.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = api.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the shared client sees the change through its own monitor
+	found := false
+	timeout := time.After(5 * time.Second)
+	for !found {
+		select {
+		case ev := <-events:
+			ls, ok := ev.New.(*LogicalSwitch)
+			found = ev.Type == EventCreate && ok && ls.Name == LSW
+		case <-timeout:
+			t.Fatal("switch not seen by the other client")
+		}
+	}
+	found = false
+	for _, ls := range ovndbapi.GetLogicSwitches() {
+		if ls.Name == LSW {
+			found = true
+		}
+	}
+	assert.Equal(t, true, found, "test[%s]", "switch visible to other client")
+
+	cmd, err = api.LSWDel(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = api.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Close()
+	assert.Nil(t, err, "test[%s]", "close client")
+
+	_, err = NewClient(Config{Protocol: "sctp"})
+	assert.Equal(t, true, err != nil, "test[%s]", "unsupported protocol rejected")
+}
+
+func TestGetInstance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goovn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a failed connect is not kept, the next call connects again
+	_, err = GetInstance(filepath.Join(dir, "missing.ovsdb"), UNIX, "", 0, nil)
+	assert.NotNil(t, err, "test[%s]", "connect failed")
+
+	api, err := GetInstance(ovncfg.Socket, ovncfg.Protocol, ovncfg.Server, ovncfg.Port, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := GetInstance(ovncfg.Socket, ovncfg.Protocol, ovncfg.Server, ovncfg.Port, nil)
+	assert.Nil(t, err, "test[%s]", "instance returned again")
+	assert.True(t, api == again, "test[%s]", "instance shared")
+	assert.False(t, api == ovndbapi, "test[%s]", "instance independent of NewClient")
+
+	// closing the instance releases it, concurrent calls then share a
+	// single new one
+	assert.Nil(t, api.Close(), "test[%s]", "close instance")
+	instances := make(chan OVNDBApi, 4)
+	for i := 0; i < cap(instances); i++ {
+		go func() {
+			instance, err := GetInstance(ovncfg.Socket, ovncfg.Protocol, ovncfg.Server, ovncfg.Port, nil)
 			if err != nil {
-				log.Fatal(err)
+				t.Error(err)
 			}
-		}
+			instances <- instance
+		}()
+	}
+	again = <-instances
+	for i := 1; i < cap(instances); i++ {
+		assert.True(t, again == <-instances, "test[%s]", "concurrent calls share the instance")
+	}
+	if again == nil {
+		t.FailNow()
 	}
-	ovndbapi = api
-	os.Exit(m.Run())
+	assert.False(t, api == again, "test[%s]", "new instance after close")
+	assert.NotEqual(t, "", again.SchemaVersion(), "test[%s]", "new instance connected")
+	assert.Nil(t, again.Close(), "test[%s]", "close new instance")
 }
 
 func TestACLs(t *testing.T) {
@@ -17,16 +17,83 @@
 package goovn
 
 import (
+	"sync"
+
 	"github.com/unistack-org/libovsdb"
 )
 
 type ovnNotifier struct {
 	odbi *ovnDBImp
+	// conn is the connection the notifier is registered on
+	conn *ovsdbConn
+	// pending holds the updates received before the monitor snapshot of
+	// conn is in the cache
+	pending *pendingUpdates
+}
+
+// pendingUpdates buffers the updates of a connection from the monitor
+// request until its reply is applied to the cache. The notifier is
+// registered before the request, so no update committed meanwhile is lost.
+type pendingUpdates struct {
+	mutex   sync.Mutex
+	applied bool
+	updates []libovsdb.TableUpdates
+}
+
+// hold buffers updates unless the snapshot is applied already
+func (pending *pendingUpdates) hold(updates libovsdb.TableUpdates) bool {
+	pending.mutex.Lock()
+	defer pending.mutex.Unlock()
+	if pending.applied {
+		return false
+	}
+	pending.updates = append(pending.updates, updates)
+	return true
+}
+
+// next returns the buffered updates, and marks the snapshot applied once
+// there are none left
+func (pending *pendingUpdates) next() []libovsdb.TableUpdates {
+	pending.mutex.Lock()
+	defer pending.mutex.Unlock()
+	updates := pending.updates
+	pending.updates = nil
+	if len(updates) == 0 {
+		pending.applied = true
+	}
+	return updates
+}
+
+func newOVNNotifier(odbi *ovnDBImp, conn *ovsdbConn) ovnNotifier {
+	return ovnNotifier{odbi, conn, &pendingUpdates{}}
 }
 
 func (notify ovnNotifier) Update(context interface{}, tableUpdates libovsdb.TableUpdates) {
+	if isServerUpdate(context) {
+		notify.odbi.serverUpdate(notify.conn, tableUpdates)
+		return
+	}
+	if notify.pending.hold(tableUpdates) {
+		return
+	}
 	notify.odbi.populateCache(tableUpdates)
 }
+
+// applySnapshot populates the cache with the monitor snapshot and then
+// with the updates that arrived while it was requested, in order. The
+// buffer is drained without its lock, so callbacks run as for any update.
+func (notify ovnNotifier) applySnapshot(snapshot libovsdb.TableUpdates) {
+	notify.odbi.populateCache(snapshot)
+	for {
+		updates := notify.pending.next()
+		if len(updates) == 0 {
+			return
+		}
+		for _, u := range updates {
+			notify.odbi.populateCache(u)
+		}
+	}
+}
 func (notify ovnNotifier) Locked([]interface{}) {
 }
 func (notify ovnNotifier) Stolen([]interface{}) {
@@ -35,3 +102,195 @@ func (notify ovnNotifier) Echo([]interface{}) {
 }
 func (notify ovnNotifier) Disconnected(client *libovsdb.OvsdbClient) {
 }
+func (notify ovnNotifier) connectionLost() {
+	notify.odbi.reconnect(notify.conn)
+}
+
+// rowToObject converts the cached row to the model of its table, or returns
+// the row itself for tables without one.
+func (odbi *ovnDBImp) rowToObject(table, uuid string) (interface{}, error) {
+	switch table {
+	case tableNBGlobal:
+		return odbi.RowToNBGlobal(uuid)
+	case tableLogicalRouter:
+		return odbi.RowToLogicalRouter(uuid)
+	case tableLogicalRouterPort:
+		return odbi.RowToLogicalRouterPort(uuid)
+	case tableLogicalRouterStaticRoute:
+		return odbi.RowToLogicalRouterStaticRoute(uuid)
+	case tableLogicalSwitch:
+		return odbi.RowToLogicalSwitch(uuid)
+	case tableLogicalSwitchPort:
+		return odbi.RowToLogicalPort(uuid)
+	case tableAddressSet:
+		return odbi.RowToAddressSet(uuid)
+	case tablePortGroup:
+		return odbi.RowToPortGroup(uuid)
+	case tableLoadBalancer:
+		return odbi.RowToLB(uuid)
+	case tableACL:
+		return odbi.RowToACL(uuid)
+	case tableQoS:
+		return odbi.RowToQoS(uuid)
+	case tableMeter:
+		return odbi.RowToMeter(uuid)
+	case tableMeterBand:
+		return odbi.RowToMeterBand(uuid)
+	case tableNAT:
+		return odbi.RowToNAT(uuid)
+	case tableDHCPOptions:
+		return odbi.RowToDHCPOptions(uuid)
+	case tableDNS:
+		return odbi.RowToDNS(uuid)
+	case tableGatewayChassis:
+		return odbi.RowToGatewayChassis(uuid)
+	case tableChassis:
+		return odbi.RowToChassis(uuid)
+	case tableEncap:
+		return odbi.RowToEncap(uuid)
+	case tablePortBinding:
+		return odbi.RowToPortBinding(uuid)
+	case tableDatapathBinding:
+		return odbi.RowToDatapathBinding(uuid)
+	case tableMACBinding:
+		return odbi.RowToMACBinding(uuid)
+	}
+	return odbi.cache[table][uuid], nil
+}
+
+// signal tells the callbacks about a row change: a create when there is no
+// old row, a delete when there is no new one and an update otherwise.
+// Callbacks not implementing the update interface see an update as a
+// create, the table interface sees the changes of every table.
+func (odbi *ovnDBImp) signal(table, uuid string, oldObj, newObj interface{}) {
+	if oldObj == nil && newObj == nil {
+		return
+	}
+	if odbi.callback != nil {
+		odbi.signalNB(table, oldObj, newObj)
+		if tcb, ok := odbi.callback.(OVNTableSignal); ok {
+			tcb.OnTableChange(table, uuid, oldObj, newObj)
+		}
+	}
+	if odbi.sbcallback != nil {
+		odbi.signalSB(table, oldObj, newObj)
+		if tcb, ok := odbi.sbcallback.(OVNTableSignal); ok {
+			tcb.OnTableChange(table, uuid, oldObj, newObj)
+		}
+	}
+}
+
+func (odbi *ovnDBImp) signalNB(table string, oldObj, newObj interface{}) {
+	cb := odbi.callback
+	ucb, update := cb.(OVNUpdateSignal)
+	switch table {
+	case tableLogicalRouter:
+		switch {
+		case newObj == nil:
+			cb.OnLogicalRouterDelete(oldObj.(*LogicalRouter))
+		case oldObj != nil && update:
+			ucb.OnLogicalRouterUpdate(oldObj.(*LogicalRouter), newObj.(*LogicalRouter))
+		default:
+			cb.OnLogicalRouterCreate(newObj.(*LogicalRouter))
+		}
+	case tableLogicalRouterPort:
+		switch {
+		case newObj == nil:
+			cb.OnLogicalRouterPortDelete(oldObj.(*LogicalRouterPort))
+		case oldObj != nil && update:
+			ucb.OnLogicalRouterPortUpdate(oldObj.(*LogicalRouterPort), newObj.(*LogicalRouterPort))
+		default:
+			cb.OnLogicalRouterPortCreate(newObj.(*LogicalRouterPort))
+		}
+	case tableLogicalSwitch:
+		switch {
+		case newObj == nil:
+			cb.OnLogicalSwitchDelete(oldObj.(*LogicalSwitch))
+		case oldObj != nil && update:
+			ucb.OnLogicalSwitchUpdate(oldObj.(*LogicalSwitch), newObj.(*LogicalSwitch))
+		default:
+			cb.OnLogicalSwitchCreate(newObj.(*LogicalSwitch))
+		}
+	case tableLogicalSwitchPort:
+		switch {
+		case newObj == nil:
+			cb.OnLogicalPortDelete(oldObj.(*LogicalSwitchPort))
+		case oldObj != nil && update:
+			ucb.OnLogicalPortUpdate(oldObj.(*LogicalSwitchPort), newObj.(*LogicalSwitchPort))
+		default:
+			cb.OnLogicalPortCreate(newObj.(*LogicalSwitchPort))
+		}
+	case tableACL:
+		switch {
+		case newObj == nil:
+			cb.OnACLDelete(oldObj.(*ACL))
+		case oldObj != nil && update:
+			ucb.OnACLUpdate(oldObj.(*ACL), newObj.(*ACL))
+		default:
+			cb.OnACLCreate(newObj.(*ACL))
+		}
+	case tableDHCPOptions:
+		switch {
+		case newObj == nil:
+			cb.OnDHCPOptionsDelete(oldObj.(*DHCPOptions))
+		case oldObj != nil && update:
+			ucb.OnDHCPOptionsUpdate(oldObj.(*DHCPOptions), newObj.(*DHCPOptions))
+		default:
+			cb.OnDHCPOptionsCreate(newObj.(*DHCPOptions))
+		}
+	case tableQoS:
+		qcb, ok := cb.(OVNQoSSignal)
+		switch {
+		case !ok:
+		case newObj == nil:
+			qcb.OnQoSDelete(oldObj.(*QoS))
+		case oldObj != nil:
+			qcb.OnQoSUpdate(oldObj.(*QoS), newObj.(*QoS))
+		default:
+			qcb.OnQoSCreate(newObj.(*QoS))
+		}
+	}
+}
+
+func (odbi *ovnDBImp) signalSB(table string, oldObj, newObj interface{}) {
+	cb := odbi.sbcallback
+	ucb, update := cb.(OVNSBUpdateSignal)
+	switch table {
+	case tableChassis:
+		switch {
+		case newObj == nil:
+			cb.OnChassisDelete(oldObj.(*Chassis))
+		case oldObj != nil && update:
+			ucb.OnChassisUpdate(oldObj.(*Chassis), newObj.(*Chassis))
+		default:
+			cb.OnChassisCreate(newObj.(*Chassis))
+		}
+	case tablePortBinding:
+		switch {
+		case newObj == nil:
+			cb.OnPortBindingDelete(oldObj.(*PortBinding))
+		case oldObj != nil && update:
+			ucb.OnPortBindingUpdate(oldObj.(*PortBinding), newObj.(*PortBinding))
+		default:
+			cb.OnPortBindingCreate(newObj.(*PortBinding))
+		}
+	case tableDatapathBinding:
+		switch {
+		case newObj == nil:
+			cb.OnDatapathBindingDelete(oldObj.(*DatapathBinding))
+		case oldObj != nil && update:
+			ucb.OnDatapathBindingUpdate(oldObj.(*DatapathBinding), newObj.(*DatapathBinding))
+		default:
+			cb.OnDatapathBindingCreate(newObj.(*DatapathBinding))
+		}
+	case tableMACBinding:
+		switch {
+		case newObj == nil:
+			cb.OnMACBindingDelete(oldObj.(*MACBinding))
+		case oldObj != nil && update:
+			ucb.OnMACBindingUpdate(oldObj.(*MACBinding), newObj.(*MACBinding))
+		default:
+			cb.OnMACBindingCreate(newObj.(*MACBinding))
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package goovn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+// lswUpdateRecorder also records the modifications of logical switches
+type lswUpdateRecorder struct {
+	lswRecorder
+	updated [][2]string
+}
+
+func (r *lswUpdateRecorder) OnLogicalSwitchUpdate(old, new *LogicalSwitch) {
+	r.updated = append(r.updated, [2]string{old.Name, new.Name})
+}
+func (r *lswUpdateRecorder) OnLogicalPortUpdate(old, new *LogicalSwitchPort)       {}
+func (r *lswUpdateRecorder) OnLogicalRouterUpdate(old, new *LogicalRouter)         {}
+func (r *lswUpdateRecorder) OnLogicalRouterPortUpdate(old, new *LogicalRouterPort) {}
+func (r *lswUpdateRecorder) OnACLUpdate(old, new *ACL)                             {}
+func (r *lswUpdateRecorder) OnDHCPOptionsUpdate(old, new *DHCPOptions)             {}
+
+func lswUpdate(uuid string, row libovsdb.RowUpdate) libovsdb.TableUpdates {
+	return libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableLogicalSwitch: {Rows: map[string]libovsdb.RowUpdate{uuid: row}},
+	}}
+}
+
+func TestUpdateSignal(t *testing.T) {
+	recorder := &lswUpdateRecorder{}
+	odbi := &ovnDBImp{
+		cache:    make(map[string]map[string]libovsdb.Row),
+		callback: recorder,
+	}
+
+	odbi.populateCache(lswUpdate("uuid-ls", libovsdb.RowUpdate{New: lswRow("ls1")}))
+	odbi.populateCache(lswUpdate("uuid-ls", libovsdb.RowUpdate{
+		Old: libovsdb.Row{Fields: map[string]interface{}{"name": "ls1"}},
+		New: lswRow("ls2"),
+	}))
+	odbi.populateCache(lswUpdate("uuid-ls", libovsdb.RowUpdate{Old: lswRow("ls2")}))
+	// deleting a row missing from the cache is not signaled
+	odbi.populateCache(lswUpdate("uuid-unknown", libovsdb.RowUpdate{Old: lswRow("ls3")}))
+
+	assert.Equal(t, []string{"ls1"}, recorder.created, "test[%s]", "create signaled once")
+	assert.Equal(t, [][2]string{{"ls1", "ls2"}}, recorder.updated, "test[%s]", "update with old and new row")
+	assert.Equal(t, []string{"ls2"}, recorder.deleted, "test[%s]", "delete with last known row")
+
+	// without the update interface a modification is a create
+	legacy := &lswRecorder{}
+	odbi.callback = legacy
+	odbi.populateCache(lswUpdate("uuid-ls", libovsdb.RowUpdate{New: lswRow("ls1")}))
+	odbi.populateCache(lswUpdate("uuid-ls", libovsdb.RowUpdate{New: lswRow("ls2")}))
+	assert.Equal(t, []string{"ls1", "ls2"}, legacy.created, "test[%s]", "legacy create on update")
+}
+
+// tableRecorder records the changes of every table
+type tableRecorder struct {
+	lswRecorder
+	changes []string
+	objects []interface{}
+}
+
+func (r *tableRecorder) OnTableChange(table, uuid string, old, new interface{}) {
+	kind := "update"
+	switch {
+	case old == nil:
+		kind = "create"
+	case new == nil:
+		kind = "delete"
+	}
+	r.changes = append(r.changes, table+" "+uuid+" "+kind)
+	if new != nil {
+		r.objects = append(r.objects, new)
+	}
+}
+
+func TestTableSignal(t *testing.T) {
+	recorder := &tableRecorder{}
+	odbi := &ovnDBImp{
+		cache:    make(map[string]map[string]libovsdb.Row),
+		callback: recorder,
+	}
+
+	members, _ := libovsdb.NewOvsSet([]string{"10.0.0.1", "10.0.0.2"})
+	ids, _ := libovsdb.NewOvsMap(map[string]string{})
+	odbi.populateCache(libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableAddressSet: {Rows: map[string]libovsdb.RowUpdate{"uuid-as": {New: libovsdb.Row{Fields: map[string]interface{}{
+			"name":         "as1",
+			"addresses":    *members,
+			"external_ids": *ids,
+		}}}}},
+	}})
+	sslRow := libovsdb.Row{Fields: map[string]interface{}{"private_key": "key"}}
+	odbi.populateCache(libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableSSL: {Rows: map[string]libovsdb.RowUpdate{"uuid-ssl": {New: sslRow}}},
+	}})
+	odbi.populateCache(libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableSSL: {Rows: map[string]libovsdb.RowUpdate{"uuid-ssl": {Old: sslRow}}},
+	}})
+	odbi.populateCache(lswUpdate("uuid-ls", libovsdb.RowUpdate{New: lswRow("ls1")}))
+	odbi.populateCache(lswUpdate("uuid-ls", libovsdb.RowUpdate{New: lswRow("ls2")}))
+
+	assert.Equal(t, []string{
+		"Address_Set uuid-as create",
+		"SSL uuid-ssl create",
+		"SSL uuid-ssl delete",
+		"Logical_Switch uuid-ls create",
+		"Logical_Switch uuid-ls update",
+	}, recorder.changes, "test[%s]", "changes of every table")
+	assert.Equal(t, &AddressSet{
+		UUID:       "uuid-as",
+		Name:       "as1",
+		Addresses:  []string{"10.0.0.1", "10.0.0.2"},
+		ExternalID: map[interface{}]interface{}{},
+	}, recorder.objects[0], "test[%s]", "modeled table as its type")
+	assert.Equal(t, sslRow, recorder.objects[1], "test[%s]", "other table as a row")
+	assert.Equal(t, []string{"ls1", "ls2"}, recorder.created, "test[%s]", "typed callbacks still called")
+}
+
+// lswReader reads the cache from its callback
+type lswReader struct {
+	lswRecorder
+	odbi   *ovnDBImp
+	counts []int
+}
+
+func (r *lswReader) OnLogicalSwitchCreate(ls *LogicalSwitch) {
+	r.counts = append(r.counts, len(r.odbi.GetLogicSwitches()))
+}
+
+func TestSignalOutsideLock(t *testing.T) {
+	reader := &lswReader{}
+	odbi := &ovnDBImp{
+		cache:    make(map[string]map[string]libovsdb.Row),
+		callback: reader,
+	}
+	reader.odbi = odbi
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		odbi.populateCache(libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+			tableLogicalSwitch: {Rows: map[string]libovsdb.RowUpdate{
+				"uuid-ls1": {New: lswRow("ls1")},
+				"uuid-ls2": {New: lswRow("ls2")},
+			}},
+		}})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback reading the cache deadlocked")
+	}
+	// both rows are cached before the callbacks run
+	assert.Equal(t, []int{2, 2}, reader.counts, "test[%s]", "getters in callback")
+}
+
+// qosRecorder records the changes of QoS rules
+type qosRecorder struct {
+	lswRecorder
+	changes []string
+}
+
+func (r *qosRecorder) OnQoSCreate(qos *QoS) {
+	r.changes = append(r.changes, "create "+qos.Match)
+}
+func (r *qosRecorder) OnQoSDelete(qos *QoS) {
+	r.changes = append(r.changes, "delete "+qos.Match)
+}
+func (r *qosRecorder) OnQoSUpdate(old, new *QoS) {
+	r.changes = append(r.changes, "update "+old.Match+" "+new.Match)
+}
+
+func qosUpdate(uuid string, row libovsdb.RowUpdate) libovsdb.TableUpdates {
+	return libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableQoS: {Rows: map[string]libovsdb.RowUpdate{uuid: row}},
+	}}
+}
+
+func qosRow(match string) libovsdb.Row {
+	return libovsdb.Row{Fields: map[string]interface{}{
+		"priority":  1000,
+		"direction": "from-lport",
+		"match":     match,
+	}}
+}
+
+func TestQoSSignal(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		callback OVNSignal
+		changes  []string
+	}{
+		{"qos callbacks", &qosRecorder{}, []string{"create ip4", "update ip4 ip6", "delete ip6"}},
+		// QoS rules are not signaled to callbacks without them
+		{"no qos callbacks", &lswRecorder{}, nil},
+	} {
+		odbi := &ovnDBImp{
+			cache:    make(map[string]map[string]libovsdb.Row),
+			callback: tc.callback,
+		}
+		odbi.populateCache(qosUpdate("uuid-qos", libovsdb.RowUpdate{New: qosRow("ip4")}))
+		odbi.populateCache(qosUpdate("uuid-qos", libovsdb.RowUpdate{Old: qosRow("ip4"), New: qosRow("ip6")}))
+		odbi.populateCache(qosUpdate("uuid-qos", libovsdb.RowUpdate{Old: qosRow("ip6")}))
+		if recorder, ok := tc.callback.(*qosRecorder); ok {
+			assert.Equal(t, tc.changes, recorder.changes, "test[%s]", tc.name)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"context"
+)
+
+// Southbound tables kept in the cache of the southbound client. Logical_Flow
+// and friends are left out on purpose, they are large and owned by northd.
+var sbMonitorTables = []string{
+	tableChassis,
+	tableEncap,
+	tablePortBinding,
+	tableDatapathBinding,
+	tableMACBinding,
+}
+
+type OVNSB struct {
+	imp *ovnDBImp
+}
+
+func newSBImp(ctx context.Context, client *ovnDBClient, cfg Config) (*ovnDBImp, error) {
+	sbimp := newOVNDBImp(client, SBDB, sbMonitorTables, cfg)
+	err := sbimp.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sbimp.sbcallback = cfg.SBSignalCB
+	return sbimp, nil
+}
+
+// NewSBClient opens a connection to the southbound database. Like NewClient
+// it is independent of any other client, so NB and SB can be used side by
+// side.
+func NewSBClient(cfg Config) (OVNSBApi, error) {
+	return NewSBClientContext(context.Background(), cfg)
+}
+
+// NewSBClientContext is NewSBClient with ctx bounding the connection and
+// the monitor setup.
+func NewSBClientContext(ctx context.Context, cfg Config) (OVNSBApi, error) {
+	odb, err := newOVNDBClient(ctx, cfg, SBDB)
+	if err != nil {
+		return nil, err
+	}
+
+	imp, err := newSBImp(ctx, odb, cfg)
+	if err != nil {
+		odb.dbclient.Disconnect()
+		return nil, err
+	}
+
+	return &OVNSB{imp}, nil
+}
+
+func (osb *OVNSB) ChassisDel(name string) (*OvnCommand, error) {
+	return osb.imp.chassisDelImp(name)
+}
+
+func (osb *OVNSB) Execute(cmds ...*OvnCommand) error {
+	return osb.imp.Execute(cmds...)
+}
+
+func (osb *OVNSB) ExecuteContext(ctx context.Context, cmds ...*OvnCommand) error {
+	return osb.imp.ExecuteContext(ctx, cmds...)
+}
+
+func (osb *OVNSB) GetChassis() []*Chassis {
+	return osb.imp.GetChassis()
+}
+
+func (osb *OVNSB) GetChassisByName(name string) (*Chassis, error) {
+	return osb.imp.GetChassisByName(name)
+}
+
+func (osb *OVNSB) GetEncapsByChassis(name string) ([]*Encap, error) {
+	return osb.imp.GetEncapsByChassis(name)
+}
+
+func (osb *OVNSB) GetPortBindings() []*PortBinding {
+	return osb.imp.GetPortBindings()
+}
+
+func (osb *OVNSB) GetPortBindingByLogicalPort(lport string) (*PortBinding, error) {
+	return osb.imp.GetPortBindingByLogicalPort(lport)
+}
+
+func (osb *OVNSB) GetPortBindingsByChassis(name string) ([]*PortBinding, error) {
+	return osb.imp.GetPortBindingsByChassis(name)
+}
+
+func (osb *OVNSB) GetDatapathBindings() []*DatapathBinding {
+	return osb.imp.GetDatapathBindings()
+}
+
+func (osb *OVNSB) GetMACBindings() []*MACBinding {
+	return osb.imp.GetMACBindings()
+}
+
+func (osb *OVNSB) SetCallBack(callback OVNSBSignal) {
+	osb.imp.sbcallback = callback
+}
+
+func (osb *OVNSB) Subscribe(filter EventFilter) (<-chan Event, func()) {
+	return osb.imp.Subscribe(filter)
+}
+
+func (osb *OVNSB) SchemaVersion() string {
+	return osb.imp.SchemaVersion()
+}
+
+func (osb *OVNSB) Close() error {
+	return osb.imp.close()
+}
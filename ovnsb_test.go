@@ -0,0 +1,348 @@
+package goovn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/ebay/go-ovn/ovsdbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+// sbRecorder records the southbound objects it is signaled for, as
+// "<event> <table> <name>"
+type sbRecorder struct {
+	events []string
+}
+
+func (r *sbRecorder) record(event, table, name string) {
+	r.events = append(r.events, fmt.Sprintf("%s %s %s", event, table, name))
+}
+
+// take returns the sorted events since the last call, the changes of one
+// transaction are signaled in no particular order
+func (r *sbRecorder) take() []string {
+	events := r.events
+	r.events = nil
+	sort.Strings(events)
+	return events
+}
+
+func (r *sbRecorder) OnChassisCreate(ch *Chassis) {
+	r.record("create", tableChassis, ch.Name)
+}
+func (r *sbRecorder) OnChassisDelete(ch *Chassis) {
+	r.record("delete", tableChassis, ch.Name)
+}
+func (r *sbRecorder) OnPortBindingCreate(pb *PortBinding) {
+	r.record("create", tablePortBinding, pb.LogicalPort)
+}
+func (r *sbRecorder) OnPortBindingDelete(pb *PortBinding) {
+	r.record("delete", tablePortBinding, pb.LogicalPort)
+}
+func (r *sbRecorder) OnDatapathBindingCreate(dp *DatapathBinding) {
+	r.record("create", tableDatapathBinding, fmt.Sprint(dp.TunnelKey))
+}
+func (r *sbRecorder) OnDatapathBindingDelete(dp *DatapathBinding) {
+	r.record("delete", tableDatapathBinding, fmt.Sprint(dp.TunnelKey))
+}
+func (r *sbRecorder) OnMACBindingCreate(mb *MACBinding) {
+	r.record("create", tableMACBinding, mb.IP)
+}
+func (r *sbRecorder) OnMACBindingDelete(mb *MACBinding) {
+	r.record("delete", tableMACBinding, mb.IP)
+}
+
+// sbUpdateRecorder also records modifications
+type sbUpdateRecorder struct {
+	sbRecorder
+}
+
+func (r *sbUpdateRecorder) OnChassisUpdate(old, new *Chassis) {
+	r.record("update", tableChassis, new.Name)
+}
+func (r *sbUpdateRecorder) OnPortBindingUpdate(old, new *PortBinding) {
+	r.record("update", tablePortBinding, new.LogicalPort)
+}
+func (r *sbUpdateRecorder) OnDatapathBindingUpdate(old, new *DatapathBinding) {
+	r.record("update", tableDatapathBinding, fmt.Sprint(new.TunnelKey))
+}
+func (r *sbUpdateRecorder) OnMACBindingUpdate(old, new *MACBinding) {
+	r.record("update", tableMACBinding, new.IP)
+}
+
+// newSBTestClient returns a southbound client of an in-memory database
+func newSBTestClient(t *testing.T, callback OVNSBSignal) (*OVNSB, *ovsdbtest.Server) {
+	server, err := ovsdbtest.NewSBServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := newOVSDBConn(context.Background(), server.Pipe())
+	if err != nil {
+		t.Fatal(err)
+	}
+	imp, err := newSBImp(context.Background(), &ovnDBClient{dbclient: conn}, Config{DisableReconnect: true, SBSignalCB: callback})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &OVNSB{imp}, server
+}
+
+func sbTransact(t *testing.T, sb *OVNSB, ops ...libovsdb.Operation) {
+	_, err := sb.imp.transact(context.Background(), ops...)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sbMap(m map[string]string) *libovsdb.OvsMap {
+	ovsmap, _ := libovsdb.NewOvsMap(m)
+	return ovsmap
+}
+
+func sbSet(s []string) *libovsdb.OvsSet {
+	ovsset, _ := libovsdb.NewOvsSet(s)
+	return ovsset
+}
+
+// sbSeed fills the southbound database with two chassis, a datapath with
+// two ports of which one is bound to ch1, a MAC binding and a logical flow
+func sbSeed(t *testing.T, sb *OVNSB) {
+	sbTransact(t, sb,
+		libovsdb.Operation{Op: opInsert, Table: tableEncap, UUIDName: "e1", Row: map[string]interface{}{
+			"type": "geneve", "ip": "10.0.0.1", "chassis_name": "ch1"}},
+		libovsdb.Operation{Op: opInsert, Table: tableChassis, UUIDName: "ch1", Row: map[string]interface{}{
+			"name": "ch1", "hostname": "host1", "encaps": libovsdb.UUID{GoUUID: "e1"}}},
+		libovsdb.Operation{Op: opInsert, Table: tableEncap, UUIDName: "e2", Row: map[string]interface{}{
+			"type": "geneve", "ip": "10.0.0.2", "chassis_name": "ch2"}},
+		libovsdb.Operation{Op: opInsert, Table: tableChassis, Row: map[string]interface{}{
+			"name": "ch2", "hostname": "host2", "encaps": libovsdb.UUID{GoUUID: "e2"}}},
+		libovsdb.Operation{Op: opInsert, Table: tableDatapathBinding, UUIDName: "dp1", Row: map[string]interface{}{
+			"tunnel_key": 1, "external_ids": sbMap(map[string]string{"name": "ls1"})}},
+		libovsdb.Operation{Op: opInsert, Table: tablePortBinding, Row: map[string]interface{}{
+			"logical_port": "lp1", "datapath": libovsdb.UUID{GoUUID: "dp1"}, "tunnel_key": 1,
+			"chassis": libovsdb.UUID{GoUUID: "ch1"}, "mac": sbSet([]string{"00:00:00:00:00:01 10.0.0.10"})}},
+		libovsdb.Operation{Op: opInsert, Table: tablePortBinding, Row: map[string]interface{}{
+			"logical_port": "lp2", "datapath": libovsdb.UUID{GoUUID: "dp1"}, "tunnel_key": 2}},
+		libovsdb.Operation{Op: opInsert, Table: tableMACBinding, Row: map[string]interface{}{
+			"logical_port": "lp1", "ip": "10.0.0.20", "mac": "00:00:00:00:00:02", "datapath": libovsdb.UUID{GoUUID: "dp1"}}},
+		libovsdb.Operation{Op: opInsert, Table: "Logical_Flow", Row: map[string]interface{}{
+			"logical_datapath": libovsdb.UUID{GoUUID: "dp1"}, "pipeline": "ingress", "table_id": 0,
+			"priority": 100, "match": "1", "actions": "next;"}},
+	)
+}
+
+func TestSBMonitorTables(t *testing.T) {
+	sb, server := newSBTestClient(t, nil)
+	defer server.Close()
+	defer sb.Close()
+	sbSeed(t, sb)
+
+	sb.imp.cachemutex.RLock()
+	defer sb.imp.cachemutex.RUnlock()
+	for _, table := range sbMonitorTables {
+		assert.NotEqual(t, 0, len(sb.imp.cache[table]), "test[%s]", "rows of "+table+" cached")
+	}
+	for table := range sb.imp.cache {
+		assert.Contains(t, sbMonitorTables, table, "test[%s]", "only monitored tables cached")
+	}
+}
+
+func TestSBGetters(t *testing.T) {
+	sb, server := newSBTestClient(t, nil)
+	defer server.Close()
+	defer sb.Close()
+	sbSeed(t, sb)
+
+	assert.Equal(t, 2, len(sb.GetChassis()), "test[%s]", "GetChassis")
+	assert.Equal(t, 2, len(sb.GetPortBindings()), "test[%s]", "GetPortBindings")
+
+	chassisTests := []struct {
+		name     string
+		hostname string
+		encaps   []string
+		err      error
+	}{
+		{"ch1", "host1", []string{"10.0.0.1"}, nil},
+		{"ch2", "host2", []string{"10.0.0.2"}, nil},
+		{"ch3", "", nil, ErrorNotFound},
+	}
+	for _, test := range chassisTests {
+		ch, err := sb.GetChassisByName(test.name)
+		assert.Equal(t, test.err, err, "test[%s]", "GetChassisByName "+test.name)
+		if err == nil {
+			assert.Equal(t, test.hostname, ch.Hostname, "test[%s]", "hostname of "+test.name)
+			assert.Equal(t, 1, len(ch.Encaps), "test[%s]", "encaps of "+test.name)
+		}
+		encaps, err := sb.GetEncapsByChassis(test.name)
+		assert.Equal(t, test.err, err, "test[%s]", "GetEncapsByChassis "+test.name)
+		var ips []string
+		for _, encap := range encaps {
+			assert.Equal(t, "geneve", encap.Type, "test[%s]", "encap type of "+test.name)
+			assert.Equal(t, test.name, encap.ChassisName, "test[%s]", "encap chassis of "+test.name)
+			ips = append(ips, encap.IP)
+		}
+		assert.Equal(t, test.encaps, ips, "test[%s]", "encap ips of "+test.name)
+	}
+
+	ch1, err := sb.GetChassisByName("ch1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dps := sb.GetDatapathBindings()
+	if !assert.Equal(t, 1, len(dps), "test[%s]", "GetDatapathBindings") {
+		t.FailNow()
+	}
+	assert.Equal(t, 1, dps[0].TunnelKey, "test[%s]", "datapath tunnel key")
+	assert.Equal(t, "ls1", dps[0].ExternalID["name"], "test[%s]", "datapath external_ids")
+
+	pbTests := []struct {
+		lport   string
+		chassis string
+		key     int
+		mac     []string
+		err     error
+	}{
+		{"lp1", ch1.UUID, 1, []string{"00:00:00:00:00:01 10.0.0.10"}, nil},
+		{"lp2", "", 2, []string{}, nil},
+		{"lp3", "", 0, nil, ErrorNotFound},
+	}
+	for _, test := range pbTests {
+		pb, err := sb.GetPortBindingByLogicalPort(test.lport)
+		assert.Equal(t, test.err, err, "test[%s]", "GetPortBindingByLogicalPort "+test.lport)
+		if err == nil {
+			assert.Equal(t, test.chassis, pb.Chassis, "test[%s]", "chassis of "+test.lport)
+			assert.Equal(t, dps[0].UUID, pb.Datapath, "test[%s]", "datapath of "+test.lport)
+			assert.Equal(t, test.key, pb.TunnelKey, "test[%s]", "tunnel key of "+test.lport)
+			assert.Equal(t, test.mac, pb.MAC, "test[%s]", "mac of "+test.lport)
+		}
+	}
+
+	byChassisTests := []struct {
+		chassis string
+		lports  []string
+		err     error
+	}{
+		{"ch1", []string{"lp1"}, nil},
+		{"ch2", nil, nil},
+		{"ch3", nil, ErrorNotFound},
+	}
+	for _, test := range byChassisTests {
+		pbs, err := sb.GetPortBindingsByChassis(test.chassis)
+		assert.Equal(t, test.err, err, "test[%s]", "GetPortBindingsByChassis "+test.chassis)
+		var lports []string
+		for _, pb := range pbs {
+			lports = append(lports, pb.LogicalPort)
+		}
+		assert.Equal(t, test.lports, lports, "test[%s]", "ports bound to "+test.chassis)
+	}
+
+	mbs := sb.GetMACBindings()
+	if assert.Equal(t, 1, len(mbs), "test[%s]", "GetMACBindings") {
+		assert.Equal(t, MACBinding{
+			UUID:        mbs[0].UUID,
+			LogicalPort: "lp1",
+			IP:          "10.0.0.20",
+			MAC:         "00:00:00:00:00:02",
+			Datapath:    dps[0].UUID,
+		}, *mbs[0], "test[%s]", "MAC binding")
+	}
+}
+
+func TestSBSignal(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorder interface {
+			OVNSBSignal
+			take() []string
+		}
+		modified []string
+		deleted  []string
+	}{
+		{
+			name:     "update signal",
+			recorder: &sbUpdateRecorder{},
+			modified: []string{
+				"update Chassis ch1",
+				"update Datapath_Binding 1",
+				"update MAC_Binding 10.0.0.20",
+				"update Port_Binding lp2",
+			},
+			deleted: []string{
+				"delete Chassis ch2",
+				"delete MAC_Binding 10.0.0.20",
+				"delete Port_Binding lp1",
+				"update Port_Binding lp2",
+			},
+		},
+		{
+			name:     "create and delete signal only",
+			recorder: &sbRecorder{},
+			modified: []string{
+				"create Chassis ch1",
+				"create Datapath_Binding 1",
+				"create MAC_Binding 10.0.0.20",
+				"create Port_Binding lp2",
+			},
+			deleted: []string{
+				"create Port_Binding lp2",
+				"delete Chassis ch2",
+				"delete MAC_Binding 10.0.0.20",
+				"delete Port_Binding lp1",
+			},
+		},
+	}
+	for _, test := range tests {
+		sb, server := newSBTestClient(t, test.recorder)
+		sbSeed(t, sb)
+		assert.Equal(t, []string{
+			"create Chassis ch1",
+			"create Chassis ch2",
+			"create Datapath_Binding 1",
+			"create MAC_Binding 10.0.0.20",
+			"create Port_Binding lp1",
+			"create Port_Binding lp2",
+		}, test.recorder.take(), "test[%s]", test.name+": created")
+
+		ch2, err := sb.GetChassisByName("ch2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sbTransact(t, sb,
+			libovsdb.Operation{Op: opUpdate, Table: tableChassis,
+				Where: []interface{}{libovsdb.NewCondition("name", "==", "ch1")},
+				Row:   map[string]interface{}{"hostname": "host1b"}},
+			libovsdb.Operation{Op: opUpdate, Table: tablePortBinding,
+				Where: []interface{}{libovsdb.NewCondition("logical_port", "==", "lp2")},
+				Row:   map[string]interface{}{"chassis": libovsdb.UUID{GoUUID: ch2.UUID}}},
+			libovsdb.Operation{Op: opUpdate, Table: tableDatapathBinding,
+				Where: []interface{}{libovsdb.NewCondition("tunnel_key", "==", 1)},
+				Row:   map[string]interface{}{"external_ids": sbMap(map[string]string{"name": "ls1b"})}},
+			libovsdb.Operation{Op: opUpdate, Table: tableMACBinding,
+				Where: []interface{}{libovsdb.NewCondition("ip", "==", "10.0.0.20")},
+				Row:   map[string]interface{}{"mac": "00:00:00:00:00:03"}},
+		)
+		assert.Equal(t, test.modified, test.recorder.take(), "test[%s]", test.name+": modified")
+
+		// deleting ch2 clears the weak reference of lp2 to it
+		cmd, err := sb.ChassisDel("ch2")
+		assert.Nil(t, err, "test[%s]", "ChassisDel")
+		assert.Nil(t, sb.Execute(cmd), "test[%s]", "execute ChassisDel")
+		sbTransact(t, sb,
+			libovsdb.Operation{Op: opDelete, Table: tableMACBinding,
+				Where: []interface{}{libovsdb.NewCondition("ip", "==", "10.0.0.20")}},
+			libovsdb.Operation{Op: opDelete, Table: tablePortBinding,
+				Where: []interface{}{libovsdb.NewCondition("logical_port", "==", "lp1")}},
+		)
+		assert.Equal(t, test.deleted, test.recorder.take(), "test[%s]", test.name+": deleted")
+		pb, err := sb.GetPortBindingByLogicalPort("lp2")
+		if assert.Nil(t, err, "test[%s]", test.name+": lp2 kept") {
+			assert.Equal(t, "", pb.Chassis, "test[%s]", test.name+": lp2 unbound")
+		}
+
+		sb.Close()
+		server.Close()
+	}
+}
@@ -0,0 +1,281 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/cenkalti/rpc2/jsonrpc"
+	"github.com/unistack-org/libovsdb"
+)
+
+// connHandler receives the notifications of an ovsdbConn
+type connHandler interface {
+	Update(context interface{}, tableUpdates libovsdb.TableUpdates)
+	// connectionLost is called once the connection is closed
+	connectionLost()
+}
+
+// ovsdbConn is a JSON-RPC connection to an ovsdb-server, speaking the
+// libovsdb notation. libovsdb's client cannot be used for ssl remotes: it
+// dials by itself with a TLS configuration built from environment variables
+// and skipping server verification, and it cannot be created on a
+// connection dialed here since its constructor is unexported.
+type ovsdbConn struct {
+	rpcClient     *rpc2.Client
+	schemas       map[string]libovsdb.DatabaseSchema
+	schemasMutex  sync.RWMutex
+	handlers      []connHandler
+	handlersMutex sync.Mutex
+}
+
+// newOVSDBConn runs the JSON-RPC protocol over conn and fetches the schema
+// of every database on the server.
+func newOVSDBConn(ctx context.Context, conn net.Conn) (*ovsdbConn, error) {
+	c := rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
+	c.SetBlocking(true)
+	ovs := &ovsdbConn{
+		rpcClient: c,
+		schemas:   make(map[string]libovsdb.DatabaseSchema),
+	}
+	c.Handle("echo", ovs.echo)
+	c.Handle("update", ovs.update)
+	go c.Run()
+	go ovs.waitDisconnect()
+
+	dbs, err := ovs.ListDbs(ctx)
+	if err != nil {
+		ovs.Disconnect()
+		return nil, err
+	}
+	for _, db := range dbs {
+		_, err = ovs.GetSchema(ctx, db)
+		if err != nil {
+			ovs.Disconnect()
+			return nil, err
+		}
+	}
+	return ovs, nil
+}
+
+// RFC 7047 : echo, the inactivity probe of the server
+func (ovs *ovsdbConn) echo(client *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+	*reply = args
+	return nil
+}
+
+// RFC 7047 : update notification, params are [<json-value>, <table-updates>]
+func (ovs *ovsdbConn) update(client *rpc2.Client, params []interface{}, reply *interface{}) error {
+	if len(params) < 2 {
+		return errors.New("invalid update message")
+	}
+	b, err := json.Marshal(params[1])
+	if err != nil {
+		return err
+	}
+	var raw map[string]map[string]libovsdb.RowUpdate
+	err = json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+
+	tableUpdates := rawToTableUpdates(raw)
+	for _, handler := range ovs.getHandlers() {
+		handler.Update(params, tableUpdates)
+	}
+	return nil
+}
+
+func (ovs *ovsdbConn) waitDisconnect() {
+	<-ovs.rpcClient.DisconnectNotify()
+	for _, handler := range ovs.getHandlers() {
+		handler.connectionLost()
+	}
+}
+
+func (ovs *ovsdbConn) getHandlers() []connHandler {
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
+	return append([]connHandler{}, ovs.handlers...)
+}
+
+// Register adds a handler of the notifications of the connection
+func (ovs *ovsdbConn) Register(handler connHandler) {
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
+	ovs.handlers = append(ovs.handlers, handler)
+}
+
+// TimeoutError is returned when the context of a request is done before
+// its reply arrived. The request is abandoned, a transaction may still be
+// committed by the server.
+type TimeoutError struct {
+	// Method is the JSON-RPC method of the request, or "connect"
+	Method string
+	// Err is context.DeadlineExceeded or context.Canceled
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("ovsdb %s aborted: %v", e.Method, e.Err)
+}
+
+// Timeout tells if the deadline of the context expired, as opposed to the
+// context being canceled.
+func (e *TimeoutError) Timeout() bool {
+	return e.Err == context.DeadlineExceeded
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrorTimeout when the deadline expired
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrorTimeout && e.Timeout()
+}
+
+// call sends the request and waits for its reply until ctx is done
+func (ovs *ovsdbConn) call(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	select {
+	case <-ctx.Done():
+		return &TimeoutError{Method: method, Err: ctx.Err()}
+	default:
+	}
+	call := ovs.rpcClient.Go(method, args, reply, make(chan *rpc2.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-ctx.Done():
+		return &TimeoutError{Method: method, Err: ctx.Err()}
+	}
+}
+
+// RFC 7047 : list_dbs
+func (ovs *ovsdbConn) ListDbs(ctx context.Context) ([]string, error) {
+	var dbs []string
+	err := ovs.call(ctx, "list_dbs", []interface{}{}, &dbs)
+	return dbs, err
+}
+
+// RFC 7047 : get_schema
+func (ovs *ovsdbConn) GetSchema(ctx context.Context, db string) (*libovsdb.DatabaseSchema, error) {
+	var reply libovsdb.DatabaseSchema
+	err := ovs.call(ctx, "get_schema", libovsdb.NewGetSchemaArgs(db), &reply)
+	if err != nil {
+		return nil, err
+	}
+	ovs.schemasMutex.Lock()
+	ovs.schemas[db] = reply
+	ovs.schemasMutex.Unlock()
+	return &reply, nil
+}
+
+// Schema returns the schema of db fetched by GetSchema
+func (ovs *ovsdbConn) Schema(db string) (libovsdb.DatabaseSchema, bool) {
+	ovs.schemasMutex.RLock()
+	defer ovs.schemasMutex.RUnlock()
+	schema, ok := ovs.schemas[db]
+	return schema, ok
+}
+
+// RFC 7047 : transact
+func (ovs *ovsdbConn) Transact(ctx context.Context, db string, operation ...libovsdb.Operation) ([]libovsdb.OperationResult, error) {
+	schema, ok := ovs.Schema(db)
+	if !ok {
+		return nil, fmt.Errorf("schema of database %s not found", db)
+	}
+	err := validateOperations(schema, operation...)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply []libovsdb.OperationResult
+	err = ovs.call(ctx, "transact", libovsdb.NewTransactArgs(db, operation...), &reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// MonitorAll monitors every column of every table of db
+func (ovs *ovsdbConn) MonitorAll(ctx context.Context, db string, jsonContext interface{}) (*libovsdb.TableUpdates, error) {
+	schema, ok := ovs.Schema(db)
+	if !ok {
+		return nil, fmt.Errorf("schema of database %s not found", db)
+	}
+
+	requests := make(map[string]libovsdb.MonitorRequest)
+	for table, tableSchema := range schema.Tables {
+		var columns []string
+		for column := range tableSchema.Columns {
+			columns = append(columns, column)
+		}
+		requests[table] = libovsdb.MonitorRequest{
+			Columns: columns,
+			Select: libovsdb.MonitorSelect{
+				Initial: true,
+				Insert:  true,
+				Delete:  true,
+				Modify:  true,
+			}}
+	}
+	return ovs.Monitor(ctx, db, jsonContext, requests)
+}
+
+// RFC 7047 : monitor
+func (ovs *ovsdbConn) Monitor(ctx context.Context, db string, jsonContext interface{}, requests map[string]libovsdb.MonitorRequest) (*libovsdb.TableUpdates, error) {
+	var raw map[string]map[string]libovsdb.RowUpdate
+	err := ovs.call(ctx, "monitor", libovsdb.NewMonitorArgs(db, jsonContext, requests), &raw)
+	if err != nil {
+		return nil, err
+	}
+	tableUpdates := rawToTableUpdates(raw)
+	return &tableUpdates, nil
+}
+
+// RFC 7047 : monitor_cancel
+func (ovs *ovsdbConn) MonitorCancel(ctx context.Context, db string, jsonContext interface{}) error {
+	var reply libovsdb.OperationResult
+	err := ovs.call(ctx, "monitor_cancel", libovsdb.NewMonitorCancelArgs(jsonContext), &reply)
+	if err != nil {
+		return err
+	}
+	if reply.Error != "" {
+		return fmt.Errorf("monitor_cancel failed: %s", reply.Error)
+	}
+	return nil
+}
+
+// Disconnect closes the connection, the handlers are told asynchronously
+func (ovs *ovsdbConn) Disconnect() {
+	ovs.rpcClient.Close()
+}
+
+func rawToTableUpdates(raw map[string]map[string]libovsdb.RowUpdate) libovsdb.TableUpdates {
+	tableUpdates := libovsdb.TableUpdates{Updates: make(map[string]libovsdb.TableUpdate)}
+	for table, rows := range raw {
+		tableUpdates.Updates[table] = libovsdb.TableUpdate{Rows: rows}
+	}
+	return tableUpdates
+}
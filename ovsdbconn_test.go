@@ -0,0 +1,86 @@
+package goovn
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ebay/go-ovn/ovsdbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+func TestCallTimeout(t *testing.T) {
+	cli, srv := net.Pipe()
+	defer srv.Close()
+	// a server that reads the requests and never replies
+	go io.Copy(ioutil.Discard, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := newOVSDBConn(ctx, cli)
+	timeout, ok := err.(*TimeoutError)
+	assert.Equal(t, true, ok, "test[%s]: %v", "timeout error returned", err)
+	if ok {
+		assert.Equal(t, true, timeout.Timeout(), "test[%s]", "deadline exceeded")
+		assert.Equal(t, "list_dbs", timeout.Method, "test[%s]", "aborted method")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = NewClientContext(ctx, Config{Protocol: UNIX, Socket: "/nonexistent/nb.ovsdb"})
+	timeout, ok = err.(*TimeoutError)
+	assert.Equal(t, true, ok && !timeout.Timeout() && timeout.Err == context.Canceled, "test[%s]: %v", "canceled connect", err)
+}
+
+func TestTransactTimeout(t *testing.T) {
+	server, err := ovsdbtest.NewNBServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	api, err := NewClientConn(context.Background(), server.Pipe(), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer api.Close()
+	client := api.(*OVNDB)
+
+	// a wait that holds the transaction for its timeout, no switch is
+	// named like that
+	wait := &OvnCommand{Operations: []libovsdb.Operation{{
+		Op:      "wait",
+		Table:   tableLogicalSwitch,
+		Where:   []interface{}{libovsdb.NewCondition("name", "==", "never")},
+		Columns: []string{"name"},
+		Until:   "==",
+		Rows:    []map[string]interface{}{{"name": "never"}},
+		Timeout: 1000,
+	}}}
+	first := make(chan error, 1)
+	go func() {
+		first <- client.Execute(wait)
+	}()
+	// the first transaction is running once the lock is taken
+	for len(client.imp.transem) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cmd, err := client.LSWAdd(LSW)
+	assert.Nil(t, err, "test[%s]", "LSWAdd")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = client.ExecuteContext(ctx, cmd)
+	timeout, ok := err.(*TimeoutError)
+	assert.Equal(t, true, ok && timeout.Timeout(), "test[%s]: %v", "second transaction timed out", err)
+	assert.Equal(t, true, time.Since(start) < 500*time.Millisecond, "test[%s]", "deadline honored while waiting for the lock")
+
+	err = <-first
+	txerr, ok := err.(*TransactionError)
+	assert.Equal(t, true, ok && txerr.Unwrap() == ErrorTimeout, "test[%s]: %v", "wait timed out", err)
+	assert.Nil(t, client.Execute(cmd), "test[%s]", "lock released")
+}
@@ -0,0 +1,990 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	guuid "github.com/google/uuid"
+)
+
+// ovsdbError is an error of RFC 7047, sent as {"error": tag, "details": ...}
+type ovsdbError struct {
+	tag     string
+	details string
+}
+
+func newError(tag, format string, args ...interface{}) *ovsdbError {
+	return &ovsdbError{tag: tag, details: fmt.Sprintf(format, args...)}
+}
+
+func (e *ovsdbError) Error() string {
+	return e.tag + ": " + e.details
+}
+
+func (e *ovsdbError) toJSON() map[string]interface{} {
+	return map[string]interface{}{"error": e.tag, "details": e.details}
+}
+
+// waitPending is returned by a wait operation whose condition may still be
+// met before deadline
+type waitPending struct {
+	deadline time.Time
+}
+
+func (w *waitPending) Error() string {
+	return "wait pending"
+}
+
+func newUUID() uuid {
+	return uuid(guuid.New().String())
+}
+
+type row struct {
+	uuid    uuid
+	version uuid
+	fields  map[string]datum
+}
+
+// get returns the value of column, including _uuid and _version
+func (r *row) get(column string) datum {
+	switch column {
+	case "_uuid":
+		return datum{keys: []interface{}{r.uuid}}
+	case "_version":
+		return datum{keys: []interface{}{r.version}}
+	}
+	return r.fields[column]
+}
+
+// database holds the committed rows of every table. The maps are replaced,
+// never modified, by a commit, so a transaction works on copies.
+type database struct {
+	schema *dbSchema
+	tables map[string]map[uuid]*row
+}
+
+func newDatabase(schema *dbSchema) *database {
+	db := &database{
+		schema: schema,
+		tables: make(map[string]map[uuid]*row),
+	}
+	for name := range schema.tables {
+		db.tables[name] = make(map[uuid]*row)
+	}
+	return db
+}
+
+// symbolTable maps the uuid-name of inserted rows to their uuid. A name may
+// be referenced before the insert naming it.
+type symbolTable struct {
+	uuids   map[string]uuid
+	created map[string]bool
+}
+
+func (st *symbolTable) lookup(name string) uuid {
+	u, ok := st.uuids[name]
+	if !ok {
+		u = newUUID()
+		st.uuids[name] = u
+	}
+	return u
+}
+
+// txn is a transaction on a database
+type txn struct {
+	db     *database
+	tables map[string]map[uuid]*row
+	// owned are the rows copied or made by the transaction
+	owned    map[*row]bool
+	symtab   *symbolTable
+	inserted map[uuid]bool
+	started  time.Time
+	now      time.Time
+}
+
+// transact runs ops, returning their results and the tables to commit, or
+// the deadline of a wait whose condition is not met yet.
+func (db *database) transact(ops []interface{}, started, now time.Time) ([]interface{}, map[string]map[uuid]*row, time.Time) {
+	t := &txn{
+		db:       db,
+		tables:   make(map[string]map[uuid]*row),
+		owned:    make(map[*row]bool),
+		symtab:   &symbolTable{uuids: make(map[string]uuid), created: make(map[string]bool)},
+		inserted: make(map[uuid]bool),
+		started:  started,
+		now:      now,
+	}
+	for name, rows := range db.tables {
+		copied := make(map[uuid]*row, len(rows))
+		for u, r := range rows {
+			copied[u] = r
+		}
+		t.tables[name] = copied
+	}
+
+	results := make([]interface{}, len(ops))
+	for i, op := range ops {
+		result, err := t.execute(op)
+		if err != nil {
+			if pending, ok := err.(*waitPending); ok {
+				return nil, nil, pending.deadline
+			}
+			results[i] = toOVSDBError(err).toJSON()
+			return results, nil, time.Time{}
+		}
+		results[i] = result
+	}
+	if err := t.commit(); err != nil {
+		return append(results, err.toJSON()), nil, time.Time{}
+	}
+	return results, t.tables, time.Time{}
+}
+
+func toOVSDBError(err error) *ovsdbError {
+	if e, ok := err.(*ovsdbError); ok {
+		return e
+	}
+	return newError("ovsdb error", "%v", err)
+}
+
+func (t *txn) execute(v interface{}) (interface{}, error) {
+	op, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, newError("syntax error", "operation %v is not an object", v)
+	}
+	name, _ := op["op"].(string)
+	switch name {
+	case "insert":
+		return t.insert(op)
+	case "select":
+		return t.selectRows(op)
+	case "update":
+		return t.update(op)
+	case "mutate":
+		return t.mutate(op)
+	case "delete":
+		return t.delete(op)
+	case "wait":
+		return t.wait(op)
+	case "commit", "comment":
+		return map[string]interface{}{}, nil
+	case "abort":
+		return nil, newError("aborted", "aborted by request")
+	case "assert":
+		return nil, newError("not owner", "locks are not supported")
+	}
+	return nil, newError("syntax error", "unknown operation %q", name)
+}
+
+func (t *txn) table(op map[string]interface{}) (*tableSchema, map[uuid]*row, error) {
+	name, _ := op["table"].(string)
+	ts, ok := t.db.schema.tables[name]
+	if !ok {
+		return nil, nil, newError("unknown table", "No table named %s.", name)
+	}
+	return ts, t.tables[name], nil
+}
+
+// writable returns the copy of the row owned by the transaction
+func (t *txn) writable(ts *tableSchema, r *row) *row {
+	if t.owned[r] {
+		return r
+	}
+	w := &row{uuid: r.uuid, version: newUUID(), fields: make(map[string]datum, len(r.fields))}
+	for column, d := range r.fields {
+		w.fields[column] = d
+	}
+	t.owned[w] = true
+	t.tables[ts.name][r.uuid] = w
+	return w
+}
+
+// parseRow reads the columns given to insert or update
+func (t *txn) parseRow(ts *tableSchema, v interface{}, update bool) (map[string]datum, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok && v != nil {
+		return nil, newError("syntax error", "row %v is not an object", v)
+	}
+	fields := make(map[string]datum, len(obj))
+	for column, value := range obj {
+		cs, ok := ts.columns[column]
+		if !ok {
+			return nil, newError("unknown column", "No column %s in table %s.", column, ts.name)
+		}
+		if update && !cs.mutable {
+			return nil, newError("constraint violation", "Cannot update immutable column %s in table %s.", column, ts.name)
+		}
+		d, err := cs.typ.parseDatum(value, t.symtab, false)
+		if err != nil {
+			return nil, err
+		}
+		fields[column] = d
+	}
+	return fields, nil
+}
+
+func (t *txn) insert(op map[string]interface{}) (interface{}, error) {
+	ts, rows, err := t.table(op)
+	if err != nil {
+		return nil, err
+	}
+	var u uuid
+	if name, ok := op["uuid-name"].(string); ok {
+		if t.symtab.created[name] {
+			return nil, newError("duplicate uuid-name", "This uuid-name %s was already used.", name)
+		}
+		u = t.symtab.lookup(name)
+		t.symtab.created[name] = true
+	} else {
+		u = newUUID()
+	}
+	fields, err := t.parseRow(ts, op["row"], false)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &row{uuid: u, version: newUUID(), fields: make(map[string]datum, len(ts.columns))}
+	for column, cs := range ts.columns {
+		if d, ok := fields[column]; ok {
+			r.fields[column] = d
+		} else {
+			r.fields[column] = cs.typ.defaultDatum()
+		}
+	}
+	t.owned[r] = true
+	t.inserted[u] = true
+	rows[u] = r
+	return map[string]interface{}{"uuid": atomToJSON(u)}, nil
+}
+
+type condition struct {
+	column   string
+	typ      columnType
+	function string
+	arg      datum
+}
+
+func (t *txn) parseConditions(ts *tableSchema, v interface{}) ([]condition, error) {
+	where, ok := v.([]interface{})
+	if !ok && v != nil {
+		return nil, newError("syntax error", "where %v is not an array", v)
+	}
+	var conditions []condition
+	for _, w := range where {
+		c, ok := w.([]interface{})
+		if !ok || len(c) != 3 {
+			return nil, newError("syntax error", "invalid condition %v", w)
+		}
+		column, _ := c[0].(string)
+		function, _ := c[1].(string)
+		cs, ok := ts.column(column)
+		if !ok {
+			return nil, newError("syntax error", "No column %s in table %s.", column, ts.name)
+		}
+		switch function {
+		case "==", "!=", "includes", "excludes":
+		case "<", "<=", ">", ">=":
+			if cs.typ.isMap() || cs.typ.max != 1 || (cs.typ.key.atomic != typeInteger && cs.typ.key.atomic != typeReal) {
+				return nil, newError("syntax error", "Type mismatch: \"%s\" operator may not be applied to column %s.", function, column)
+			}
+		default:
+			return nil, newError("syntax error", "unknown function %q", function)
+		}
+		arg, err := cs.typ.parseDatum(c[2], t.symtab, true)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition{column: column, typ: cs.typ, function: function, arg: arg})
+	}
+	return conditions, nil
+}
+
+func (c condition) match(r *row) bool {
+	d := r.get(c.column)
+	switch c.function {
+	case "==":
+		return d.equal(c.arg)
+	case "!=":
+		return !d.equal(c.arg)
+	case "includes":
+		return d.includes(c.arg, c.typ.isMap())
+	case "excludes":
+		return d.excludes(c.arg, c.typ.isMap())
+	}
+	if len(d.keys) != 1 || len(c.arg.keys) != 1 {
+		return false
+	}
+	cmp := compareAtoms(d.keys[0], c.arg.keys[0])
+	switch c.function {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+// matching returns the rows of the table meeting the where of op, sorted
+// by uuid so results do not depend on map order
+func (t *txn) matching(ts *tableSchema, rows map[uuid]*row, op map[string]interface{}) ([]*row, error) {
+	conditions, err := t.parseConditions(ts, op["where"])
+	if err != nil {
+		return nil, err
+	}
+	var matched []*row
+	for _, r := range rows {
+		ok := true
+		for _, c := range conditions {
+			if !c.match(r) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, r)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].uuid < matched[j].uuid
+	})
+	return matched, nil
+}
+
+// parseColumns returns the columns of op, all with _uuid and _version when
+// not given
+func parseColumns(ts *tableSchema, v interface{}) ([]string, error) {
+	if v == nil {
+		columns := []string{"_uuid", "_version"}
+		for column := range ts.columns {
+			columns = append(columns, column)
+		}
+		return columns, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, newError("syntax error", "columns %v is not an array", v)
+	}
+	var columns []string
+	for _, c := range list {
+		column, _ := c.(string)
+		if _, ok := ts.column(column); !ok {
+			return nil, newError("syntax error", "No column %s in table %s.", column, ts.name)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func rowToJSON(ts *tableSchema, r *row, columns []string) map[string]interface{} {
+	obj := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		cs, _ := ts.column(column)
+		obj[column] = cs.typ.datumToJSON(r.get(column))
+	}
+	return obj
+}
+
+func (t *txn) selectRows(op map[string]interface{}) (interface{}, error) {
+	ts, rows, err := t.table(op)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := parseColumns(ts, op["columns"])
+	if err != nil {
+		return nil, err
+	}
+	matched, err := t.matching(ts, rows, op)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]interface{}, 0, len(matched))
+	for _, r := range matched {
+		result = append(result, rowToJSON(ts, r, columns))
+	}
+	return map[string]interface{}{"rows": result}, nil
+}
+
+func (t *txn) update(op map[string]interface{}) (interface{}, error) {
+	ts, rows, err := t.table(op)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := t.parseRow(ts, op["row"], true)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := t.matching(ts, rows, op)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range matched {
+		w := t.writable(ts, r)
+		for column, d := range fields {
+			w.fields[column] = d
+		}
+	}
+	return map[string]interface{}{"count": len(matched)}, nil
+}
+
+type mutation struct {
+	column  *columnSchema
+	mutator string
+	arg     datum
+	// keys is set when a map is mutated by deleting a set of keys
+	keys bool
+}
+
+func (t *txn) parseMutations(ts *tableSchema, v interface{}) ([]mutation, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, newError("syntax error", "mutations %v is not an array", v)
+	}
+	var mutations []mutation
+	for _, m := range list {
+		mu, ok := m.([]interface{})
+		if !ok || len(mu) != 3 {
+			return nil, newError("syntax error", "invalid mutation %v", m)
+		}
+		column, _ := mu[0].(string)
+		mutator, _ := mu[1].(string)
+		cs, ok := ts.columns[column]
+		if !ok {
+			return nil, newError("syntax error", "No column %s in table %s.", column, ts.name)
+		}
+		if !cs.mutable {
+			return nil, newError("constraint violation", "Cannot mutate immutable column %s in table %s.", column, ts.name)
+		}
+		parsed := mutation{column: cs, mutator: mutator}
+		var err error
+		switch mutator {
+		case "+=", "-=", "*=", "/=", "%=":
+			atomic := cs.typ.key.atomic
+			if cs.typ.isMap() || (atomic != typeInteger && atomic != typeReal) || (mutator == "%=" && atomic != typeInteger) {
+				return nil, newError("constraint violation", "Type mismatch: \"%s\" may not be applied to column %s.", mutator, column)
+			}
+			// the argument is not bound by the constraints of the column,
+			// only the result is
+			key, _ := parseBaseType(atomic)
+			scalar := columnType{key: *key, min: 1, max: 1}
+			parsed.arg, err = scalar.parseDatum(mu[2], t.symtab, false)
+		case "insert":
+			parsed.arg, err = cs.typ.parseDatum(mu[2], t.symtab, true)
+		case "delete":
+			if pair, ok := mu[2].([]interface{}); cs.typ.isMap() && (!ok || len(pair) != 2 || pair[0] != "map") {
+				keys := columnType{key: cs.typ.key, min: 0, max: -1}
+				parsed.arg, err = keys.parseDatum(mu[2], t.symtab, true)
+				parsed.keys = true
+			} else {
+				parsed.arg, err = cs.typ.parseDatum(mu[2], t.symtab, true)
+			}
+		default:
+			return nil, newError("syntax error", "unknown mutator %q", mutator)
+		}
+		if err != nil {
+			return nil, err
+		}
+		mutations = append(mutations, parsed)
+	}
+	return mutations, nil
+}
+
+func arithmetic(mutator string, a, b interface{}) (interface{}, error) {
+	switch a := a.(type) {
+	case int64:
+		b := b.(int64)
+		var r int64
+		switch mutator {
+		case "+=":
+			r = a + b
+			if (r > a) != (b > 0) {
+				return nil, newError("range error", "Result of %d + %d overflows", a, b)
+			}
+		case "-=":
+			r = a - b
+			if (r < a) != (b > 0) {
+				return nil, newError("range error", "Result of %d - %d overflows", a, b)
+			}
+		case "*=":
+			r = a * b
+			if a != 0 && (r/a != b || (a == -1 && b == math.MinInt64)) {
+				return nil, newError("range error", "Result of %d * %d overflows", a, b)
+			}
+		case "/=", "%=":
+			if b == 0 {
+				return nil, newError("domain error", "Division by zero.")
+			}
+			if mutator == "/=" {
+				r = a / b
+			} else {
+				r = a % b
+			}
+		}
+		return r, nil
+	case float64:
+		b := b.(float64)
+		var r float64
+		switch mutator {
+		case "+=":
+			r = a + b
+		case "-=":
+			r = a - b
+		case "*=":
+			r = a * b
+		case "/=":
+			if b == 0 {
+				return nil, newError("domain error", "Division by zero.")
+			}
+			r = a / b
+		}
+		if math.IsInf(r, 0) || math.IsNaN(r) {
+			return nil, newError("range error", "Result of %s is not finite", mutator)
+		}
+		return r, nil
+	}
+	return nil, newError("syntax error", "invalid arithmetic on %v", a)
+}
+
+// apply returns d mutated by m
+func (m mutation) apply(d datum) (datum, error) {
+	isMap := m.column.typ.isMap()
+	var keys, values []interface{}
+	switch m.mutator {
+	case "insert":
+		keys = append(keys, d.keys...)
+		if isMap {
+			values = append([]interface{}{}, d.values...)
+		}
+		for i, key := range m.arg.keys {
+			if d.find(key) >= 0 {
+				continue
+			}
+			keys = append(keys, key)
+			if isMap {
+				values = append(values, m.arg.values[i])
+			}
+		}
+	case "delete":
+		if isMap {
+			values = []interface{}{}
+		}
+		for i, key := range d.keys {
+			j := m.arg.find(key)
+			if j >= 0 && (!isMap || m.keys || compareAtoms(m.arg.values[j], d.values[i]) == 0) {
+				continue
+			}
+			keys = append(keys, key)
+			if isMap {
+				values = append(values, d.values[i])
+			}
+		}
+	default:
+		for _, key := range d.keys {
+			r, err := arithmetic(m.mutator, key, m.arg.keys[0])
+			if err != nil {
+				return datum{}, err
+			}
+			keys = append(keys, r)
+		}
+	}
+	if keys == nil {
+		keys = []interface{}{}
+	}
+	result, err := newDatum(keys, values)
+	if err != nil {
+		return datum{}, newError("constraint violation", "Result of \"%s\" operation contains duplicates", m.mutator)
+	}
+	if err := m.column.typ.checkDatum(result); err != nil {
+		return datum{}, err
+	}
+	return result, nil
+}
+
+func (t *txn) mutate(op map[string]interface{}) (interface{}, error) {
+	ts, rows, err := t.table(op)
+	if err != nil {
+		return nil, err
+	}
+	mutations, err := t.parseMutations(ts, op["mutations"])
+	if err != nil {
+		return nil, err
+	}
+	matched, err := t.matching(ts, rows, op)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range matched {
+		w := t.writable(ts, r)
+		for _, m := range mutations {
+			d, err := m.apply(w.fields[m.column.name])
+			if err != nil {
+				return nil, err
+			}
+			w.fields[m.column.name] = d
+		}
+	}
+	return map[string]interface{}{"count": len(matched)}, nil
+}
+
+func (t *txn) delete(op map[string]interface{}) (interface{}, error) {
+	ts, rows, err := t.table(op)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := t.matching(ts, rows, op)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range matched {
+		delete(rows, r.uuid)
+	}
+	return map[string]interface{}{"count": len(matched)}, nil
+}
+
+func (t *txn) wait(op map[string]interface{}) (interface{}, error) {
+	ts, rows, err := t.table(op)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := parseColumns(ts, op["columns"])
+	if err != nil {
+		return nil, err
+	}
+	until, _ := op["until"].(string)
+	if until != "==" && until != "!=" {
+		return nil, newError("syntax error", "until must be \"==\" or \"!=\"")
+	}
+	expected, ok := op["rows"].([]interface{})
+	if !ok {
+		return nil, newError("syntax error", "rows %v is not an array", op["rows"])
+	}
+	var want []map[string]datum
+	for _, e := range expected {
+		obj, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, newError("syntax error", "row %v is not an object", e)
+		}
+		fields := make(map[string]datum)
+		for _, column := range columns {
+			cs, _ := ts.column(column)
+			value, ok := obj[column]
+			if !ok {
+				return nil, newError("syntax error", "row %v has no column %s", e, column)
+			}
+			d, err := cs.typ.parseDatum(value, t.symtab, false)
+			if err != nil {
+				return nil, err
+			}
+			fields[column] = d
+		}
+		want = append(want, fields)
+	}
+	matched, err := t.matching(ts, rows, op)
+	if err != nil {
+		return nil, err
+	}
+
+	equal := len(matched) == len(want)
+	used := make([]bool, len(want))
+	for _, r := range matched {
+		if !equal {
+			break
+		}
+		found := false
+		for i, w := range want {
+			if used[i] {
+				continue
+			}
+			same := true
+			for _, column := range columns {
+				if !r.get(column).equal(w[column]) {
+					same = false
+					break
+				}
+			}
+			if same {
+				used[i], found = true, true
+				break
+			}
+		}
+		equal = found
+	}
+	if equal == (until == "==") {
+		return map[string]interface{}{}, nil
+	}
+
+	timeout, ok := op["timeout"].(float64)
+	if !ok {
+		// without timeout the wait is unbounded, it is checked again at
+		// the next commit
+		return nil, &waitPending{deadline: t.now.Add(time.Hour)}
+	}
+	deadline := t.started.Add(time.Duration(timeout) * time.Millisecond)
+	if !t.now.Before(deadline) {
+		return nil, newError("timed out", "\"wait\" timed out")
+	}
+	return nil, &waitPending{deadline: deadline}
+}
+
+// refColumns calls fn for the keys and values of the columns of ts
+// referencing other rows
+func refColumns(ts *tableSchema, fn func(cs *columnSchema, b *baseType, values bool)) {
+	for _, cs := range ts.columns {
+		if cs.typ.key.refTable != "" {
+			fn(cs, &cs.typ.key, false)
+		}
+		if cs.typ.isMap() && cs.typ.value.refTable != "" {
+			fn(cs, cs.typ.value, true)
+		}
+	}
+}
+
+func refAtoms(d datum, values bool) []interface{} {
+	if values {
+		return d.values
+	}
+	return d.keys
+}
+
+// commit enforces what RFC 7047 requires of a transaction as a whole:
+// unreferenced rows of non-root tables are deleted, weak references to
+// deleted rows removed, strong references must be to existing rows and
+// indexes unique.
+func (t *txn) commit() *ovsdbError {
+	schema := t.db.schema
+	t.collectGarbage()
+
+	for name, rows := range t.tables {
+		ts := schema.tables[name]
+		for _, r := range rows {
+			refColumns(ts, func(cs *columnSchema, b *baseType, values bool) {
+				if b.refType != refWeak {
+					return
+				}
+				d := r.fields[cs.name]
+				for _, atom := range refAtoms(d, values) {
+					if _, ok := t.tables[b.refTable][atom.(uuid)]; ok {
+						continue
+					}
+					w := t.writable(ts, r)
+					w.fields[cs.name] = dropWeakRefs(w.fields[cs.name], b.refTable, t.tables, cs.typ)
+					r = w
+					return
+				}
+			})
+		}
+	}
+
+	for _, name := range sortedTables(t.tables) {
+		ts := schema.tables[name]
+		for _, r := range sortedRows(t.tables[name]) {
+			var err *ovsdbError
+			refColumns(ts, func(cs *columnSchema, b *baseType, values bool) {
+				if err != nil || b.refType == refWeak {
+					return
+				}
+				for _, atom := range refAtoms(r.fields[cs.name], values) {
+					ref := atom.(uuid)
+					if _, ok := t.tables[b.refTable][ref]; ok {
+						continue
+					}
+					if _, ok := t.db.tables[b.refTable][ref]; ok {
+						err = newError("referential integrity violation",
+							"cannot delete %s row %s because of remaining reference from %s row %s column %s",
+							b.refTable, ref, name, r.uuid, cs.name)
+					} else {
+						err = newError("referential integrity violation",
+							"Table %s column %s row %s references nonexistent row %s in table %s.",
+							name, cs.name, r.uuid, ref, b.refTable)
+					}
+					return
+				}
+			})
+			if err != nil {
+				return err
+			}
+			if t.owned[r] {
+				for _, cs := range ts.columns {
+					if err := cs.typ.checkSize(r.fields[cs.name]); err != nil {
+						e := err.(*ovsdbError)
+						e.details = fmt.Sprintf("Table %s column %s row %s: %s", name, cs.name, r.uuid, e.details)
+						return e
+					}
+				}
+			}
+		}
+		if ts.maxRows > 0 && len(t.tables[name]) > ts.maxRows {
+			return newError("constraint violation",
+				"Transaction causes %q table to contain %d rows, greater than the schema-defined limit of %d row(s).",
+				name, len(t.tables[name]), ts.maxRows)
+		}
+		if err := t.checkIndexes(ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectGarbage deletes the rows of non-root tables no longer strongly
+// referenced, which may orphan more rows.
+func (t *txn) collectGarbage() {
+	schema := t.db.schema
+	refs := make(map[uuid]int)
+	for name, rows := range t.tables {
+		ts := schema.tables[name]
+		for _, r := range rows {
+			refColumns(ts, func(cs *columnSchema, b *baseType, values bool) {
+				if b.refType == refWeak {
+					return
+				}
+				for _, atom := range refAtoms(r.fields[cs.name], values) {
+					refs[atom.(uuid)]++
+				}
+			})
+		}
+	}
+	for {
+		collected := false
+		for name, rows := range t.tables {
+			ts := schema.tables[name]
+			if ts.isRoot {
+				continue
+			}
+			for u, r := range rows {
+				if refs[u] > 0 {
+					continue
+				}
+				delete(rows, u)
+				collected = true
+				refColumns(ts, func(cs *columnSchema, b *baseType, values bool) {
+					if b.refType == refWeak {
+						return
+					}
+					for _, atom := range refAtoms(r.fields[cs.name], values) {
+						refs[atom.(uuid)]--
+					}
+				})
+			}
+		}
+		if !collected {
+			return
+		}
+	}
+}
+
+// dropWeakRefs removes the elements of d referencing missing rows of
+// refTable
+func dropWeakRefs(d datum, refTable string, tables map[string]map[uuid]*row, typ columnType) datum {
+	exists := func(b *baseType, atom interface{}) bool {
+		if b.refTable != refTable || b.refType != refWeak {
+			return true
+		}
+		_, ok := tables[refTable][atom.(uuid)]
+		return ok
+	}
+	result := datum{keys: []interface{}{}}
+	if typ.isMap() {
+		result.values = []interface{}{}
+	}
+	for i, key := range d.keys {
+		if !exists(&typ.key, key) {
+			continue
+		}
+		if typ.isMap() && !exists(typ.value, d.values[i]) {
+			continue
+		}
+		result.keys = append(result.keys, key)
+		if typ.isMap() {
+			result.values = append(result.values, d.values[i])
+		}
+	}
+	return result
+}
+
+func (t *txn) checkIndexes(ts *tableSchema) *ovsdbError {
+	for _, index := range ts.indexes {
+		seen := make(map[string]*row)
+		for _, r := range sortedRows(t.tables[ts.name]) {
+			var values []string
+			for _, column := range index {
+				cs, _ := ts.column(column)
+				b, _ := json.Marshal(cs.typ.datumToJSON(r.get(column)))
+				values = append(values, string(b))
+			}
+			key := strings.Join(values, ", ")
+			first, ok := seen[key]
+			if !ok {
+				seen[key] = r
+				continue
+			}
+			columns := "column"
+			if len(index) > 1 {
+				columns = "columns"
+			}
+			return newError("constraint violation",
+				"Transaction causes multiple rows in %q table to have identical values (%s) for index on %s %s.  First row, with UUID %s, %s.  Second row, with UUID %s, %s.",
+				ts.name, key, columns, strings.Join(quoteAll(index), ", "),
+				first.uuid, t.rowHistory(first), r.uuid, t.rowHistory(r))
+		}
+	}
+	return nil
+}
+
+func (t *txn) rowHistory(r *row) string {
+	switch {
+	case t.inserted[r.uuid]:
+		return "was inserted by this transaction"
+	case t.owned[r]:
+		return "was modified by this transaction"
+	}
+	return "existed in the database before this transaction and was not modified by the transaction"
+}
+
+func quoteAll(strs []string) []string {
+	quoted := make([]string, 0, len(strs))
+	for _, s := range strs {
+		quoted = append(quoted, fmt.Sprintf("%q", s))
+	}
+	return quoted
+}
+
+func sortedTables(tables map[string]map[uuid]*row) []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedRows(rows map[uuid]*row) []*row {
+	sorted := make([]*row, 0, len(rows))
+	for _, r := range rows {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].uuid < sorted[j].uuid
+	})
+	return sorted
+}
@@ -0,0 +1,389 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package ovsdbtest
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// Atoms are int64, float64, bool, string or uuid values.
+type uuid string
+
+const zeroUUID uuid = "00000000-0000-0000-0000-000000000000"
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// datum is the value of a column, a sorted set of keys with their values
+// for a map. Scalars are sets of a single key. A datum is never modified
+// once built, changes make a new one.
+type datum struct {
+	keys   []interface{}
+	values []interface{}
+}
+
+func compareAtoms(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		b := b.(int64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case bool:
+		b := b.(bool)
+		switch {
+		case !a && b:
+			return -1
+		case a && !b:
+			return 1
+		}
+	case string:
+		b := b.(string)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case uuid:
+		b := b.(uuid)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	return 0
+}
+
+// newDatum sorts keys, with values for a map, and fails on duplicate keys
+func newDatum(keys, values []interface{}) (datum, error) {
+	d := datum{keys: keys, values: values}
+	sort.Sort(d)
+	for i := 1; i < len(d.keys); i++ {
+		if compareAtoms(d.keys[i-1], d.keys[i]) == 0 {
+			return datum{}, newError("ovsdb error", "duplicate key %v", d.keys[i])
+		}
+	}
+	return d, nil
+}
+
+func (d datum) Len() int { return len(d.keys) }
+func (d datum) Less(i, j int) bool {
+	return compareAtoms(d.keys[i], d.keys[j]) < 0
+}
+func (d datum) Swap(i, j int) {
+	d.keys[i], d.keys[j] = d.keys[j], d.keys[i]
+	if d.values != nil {
+		d.values[i], d.values[j] = d.values[j], d.values[i]
+	}
+}
+
+// find returns the index of key, or -1
+func (d datum) find(key interface{}) int {
+	i := sort.Search(len(d.keys), func(i int) bool {
+		return compareAtoms(d.keys[i], key) >= 0
+	})
+	if i < len(d.keys) && compareAtoms(d.keys[i], key) == 0 {
+		return i
+	}
+	return -1
+}
+
+func (d datum) equal(o datum) bool {
+	if len(d.keys) != len(o.keys) {
+		return false
+	}
+	for i := range d.keys {
+		if compareAtoms(d.keys[i], o.keys[i]) != 0 {
+			return false
+		}
+		if d.values != nil && compareAtoms(d.values[i], o.values[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// includes tells if every key, and value of a map, of o is in d
+func (d datum) includes(o datum, isMap bool) bool {
+	for i, key := range o.keys {
+		j := d.find(key)
+		if j < 0 {
+			return false
+		}
+		if isMap && compareAtoms(d.values[j], o.values[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// excludes tells if no key, or key and value of a map, of o is in d
+func (d datum) excludes(o datum, isMap bool) bool {
+	for i, key := range o.keys {
+		j := d.find(key)
+		if j >= 0 && (!isMap || compareAtoms(d.values[j], o.values[i]) == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func defaultAtom(b *baseType) interface{} {
+	switch b.atomic {
+	case typeInteger:
+		return int64(0)
+	case typeReal:
+		return float64(0)
+	case typeBoolean:
+		return false
+	case typeString:
+		return ""
+	}
+	return zeroUUID
+}
+
+// defaultDatum is the value of a column not given at insert, empty or a
+// single default atom when the column needs one.
+func (t *columnType) defaultDatum() datum {
+	if t.min == 0 {
+		if t.isMap() {
+			return datum{keys: []interface{}{}, values: []interface{}{}}
+		}
+		return datum{keys: []interface{}{}}
+	}
+	d := datum{keys: []interface{}{defaultAtom(&t.key)}}
+	if t.isMap() {
+		d.values = []interface{}{defaultAtom(t.value)}
+	}
+	return d
+}
+
+// parseAtom reads an atom in the JSON notation, named uuids are looked up
+// in symtab.
+func (b *baseType) parseAtom(v interface{}, symtab *symbolTable) (interface{}, error) {
+	switch b.atomic {
+	case typeInteger:
+		if n, ok := v.(float64); ok && n == math.Trunc(n) {
+			return int64(n), nil
+		}
+	case typeReal:
+		if n, ok := v.(float64); ok {
+			return n, nil
+		}
+	case typeBoolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case typeString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case typeUUID:
+		pair, ok := v.([]interface{})
+		if !ok || len(pair) != 2 {
+			break
+		}
+		id, ok := pair[1].(string)
+		if !ok {
+			break
+		}
+		switch pair[0] {
+		case "uuid":
+			if uuidRegexp.MatchString(id) {
+				return uuid(id), nil
+			}
+		case "named-uuid":
+			if symtab != nil {
+				return symtab.lookup(id), nil
+			}
+		}
+	}
+	return nil, newError("syntax error", "expected %s, got %v", b.atomic, v)
+}
+
+// checkAtom enforces the constraints of the base type on atom
+func (b *baseType) checkAtom(atom interface{}) error {
+	if b.enum != nil {
+		for _, e := range b.enum {
+			if compareAtoms(e, atom) == 0 {
+				return nil
+			}
+		}
+		return newError("constraint violation", "%v is not one of the allowed values", atom)
+	}
+	switch a := atom.(type) {
+	case int64:
+		if a < b.minInteger || a > b.maxInteger {
+			return newError("constraint violation", "%d is not in the range %d to %d", a, b.minInteger, b.maxInteger)
+		}
+	case float64:
+		if a < b.minReal || a > b.maxReal {
+			return newError("constraint violation", "%g is not in the range %g to %g", a, b.minReal, b.maxReal)
+		}
+	case string:
+		n := utf8.RuneCountInString(a)
+		if n < b.minLength || n > b.maxLength {
+			return newError("constraint violation", "%q length %d is not in the range %d to %d", a, n, b.minLength, b.maxLength)
+		}
+	}
+	return nil
+}
+
+// jsonSetElements returns the atoms of a set in the JSON notation, either
+// ["set", [...]] or a single atom.
+func jsonSetElements(v interface{}) ([]interface{}, error) {
+	if pair, ok := v.([]interface{}); ok && len(pair) == 2 && pair[0] == "set" {
+		elements, ok := pair[1].([]interface{})
+		if !ok {
+			return nil, newError("syntax error", "invalid set %v", v)
+		}
+		return elements, nil
+	}
+	return []interface{}{v}, nil
+}
+
+// parseDatum reads a value of the column type in the JSON notation. The
+// number of elements is not checked when relaxed, as for the argument of a
+// condition or mutation.
+func (t *columnType) parseDatum(v interface{}, symtab *symbolTable, relaxed bool) (datum, error) {
+	var keys, values []interface{}
+	if t.isMap() {
+		pair, ok := v.([]interface{})
+		if !ok || len(pair) != 2 || pair[0] != "map" {
+			return datum{}, newError("syntax error", "expected map, got %v", v)
+		}
+		elements, ok := pair[1].([]interface{})
+		if !ok {
+			return datum{}, newError("syntax error", "invalid map %v", v)
+		}
+		keys = make([]interface{}, 0, len(elements))
+		values = make([]interface{}, 0, len(elements))
+		for _, e := range elements {
+			kv, ok := e.([]interface{})
+			if !ok || len(kv) != 2 {
+				return datum{}, newError("syntax error", "invalid map pair %v", e)
+			}
+			key, err := t.key.parseAtom(kv[0], symtab)
+			if err != nil {
+				return datum{}, err
+			}
+			value, err := t.value.parseAtom(kv[1], symtab)
+			if err != nil {
+				return datum{}, err
+			}
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+	} else {
+		elements, err := jsonSetElements(v)
+		if err != nil {
+			return datum{}, err
+		}
+		keys = make([]interface{}, 0, len(elements))
+		for _, e := range elements {
+			key, err := t.key.parseAtom(e, symtab)
+			if err != nil {
+				return datum{}, err
+			}
+			keys = append(keys, key)
+		}
+	}
+	d, err := newDatum(keys, values)
+	if err != nil {
+		return datum{}, err
+	}
+	if !relaxed {
+		if err := t.checkDatum(d); err != nil {
+			return datum{}, err
+		}
+	}
+	return d, nil
+}
+
+// checkSize enforces the number of elements of the column type
+func (t *columnType) checkSize(d datum) error {
+	if len(d.keys) < t.min || (t.max >= 0 && len(d.keys) > t.max) {
+		max := "unlimited"
+		if t.max >= 0 {
+			max = fmt.Sprint(t.max)
+		}
+		return newError("constraint violation", "%d elements is not in the range %d to %s", len(d.keys), t.min, max)
+	}
+	return nil
+}
+
+// checkDatum enforces the size and the constraints of the atoms
+func (t *columnType) checkDatum(d datum) error {
+	if err := t.checkSize(d); err != nil {
+		return err
+	}
+	for i, key := range d.keys {
+		if err := t.key.checkAtom(key); err != nil {
+			return err
+		}
+		if t.isMap() {
+			if err := t.value.checkAtom(d.values[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func atomToJSON(atom interface{}) interface{} {
+	if u, ok := atom.(uuid); ok {
+		return []interface{}{"uuid", string(u)}
+	}
+	return atom
+}
+
+// datumToJSON writes d in the JSON notation, a set of one element as the
+// bare atom like ovsdb-server does.
+func (t *columnType) datumToJSON(d datum) interface{} {
+	if t.isMap() {
+		pairs := make([]interface{}, 0, len(d.keys))
+		for i, key := range d.keys {
+			pairs = append(pairs, []interface{}{atomToJSON(key), atomToJSON(d.values[i])})
+		}
+		return []interface{}{"map", pairs}
+	}
+	if len(d.keys) == 1 {
+		return atomToJSON(d.keys[0])
+	}
+	elements := make([]interface{}, 0, len(d.keys))
+	for _, key := range d.keys {
+		elements = append(elements, atomToJSON(key))
+	}
+	return []interface{}{"set", elements}
+}
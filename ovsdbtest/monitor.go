@@ -0,0 +1,160 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package ovsdbtest
+
+import (
+	"sort"
+)
+
+// monitorTable is what a monitor request selects of a table
+type monitorTable struct {
+	schema  *tableSchema
+	columns []string
+	initial bool
+	insert  bool
+	delete  bool
+	modify  bool
+}
+
+// monitor is a monitor of a connection, identified by its json-value
+type monitor struct {
+	db     string
+	id     interface{}
+	tables map[string]*monitorTable
+}
+
+// parseMonitorRequests reads the <monitor-requests> of RFC 7047, a table
+// may have a single request or an array of them.
+func parseMonitorRequests(schema *dbSchema, v interface{}) (map[string]*monitorTable, *ovsdbError) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, newError("syntax error", "monitor requests %v is not an object", v)
+	}
+	tables := make(map[string]*monitorTable)
+	for name, value := range obj {
+		ts, ok := schema.tables[name]
+		if !ok {
+			return nil, newError("syntax error", "no table named %s", name)
+		}
+		requests, ok := value.([]interface{})
+		if !ok {
+			requests = []interface{}{value}
+		}
+		mt := &monitorTable{schema: ts}
+		columns := make(map[string]bool)
+		for _, r := range requests {
+			request, ok := r.(map[string]interface{})
+			if !ok {
+				return nil, newError("syntax error", "monitor request %v is not an object", r)
+			}
+			if list, ok := request["columns"].([]interface{}); ok {
+				for _, c := range list {
+					column, _ := c.(string)
+					if _, ok := ts.columns[column]; !ok {
+						return nil, newError("syntax error", "no column %s in table %s", column, name)
+					}
+					columns[column] = true
+				}
+			} else {
+				for column := range ts.columns {
+					columns[column] = true
+				}
+			}
+			sel, ok := request["select"].(map[string]interface{})
+			if !ok {
+				mt.initial, mt.insert, mt.delete, mt.modify = true, true, true, true
+				continue
+			}
+			mt.initial = mt.initial || selected(sel, "initial")
+			mt.insert = mt.insert || selected(sel, "insert")
+			mt.delete = mt.delete || selected(sel, "delete")
+			mt.modify = mt.modify || selected(sel, "modify")
+		}
+		for column := range columns {
+			mt.columns = append(mt.columns, column)
+		}
+		sort.Strings(mt.columns)
+		tables[name] = mt
+	}
+	return tables, nil
+}
+
+// selected reads a member of <monitor-select>, true when missing
+func selected(sel map[string]interface{}, name string) bool {
+	b, ok := sel[name].(bool)
+	return !ok || b
+}
+
+func (mt *monitorTable) rowJSON(r *row) map[string]interface{} {
+	return rowToJSON(mt.schema, r, mt.columns)
+}
+
+// initial returns the <table-updates> of the rows of db
+func (m *monitor) initial(db *database) map[string]interface{} {
+	updates := make(map[string]interface{})
+	for name, mt := range m.tables {
+		if !mt.initial || len(db.tables[name]) == 0 {
+			continue
+		}
+		rows := make(map[string]interface{})
+		for u, r := range db.tables[name] {
+			rows[string(u)] = map[string]interface{}{"new": mt.rowJSON(r)}
+		}
+		updates[name] = rows
+	}
+	return updates
+}
+
+// updates returns the <table-updates> of a commit turning old into new,
+// empty when nothing monitored changed
+func (m *monitor) updates(old, new map[string]map[uuid]*row) map[string]interface{} {
+	updates := make(map[string]interface{})
+	for name, mt := range m.tables {
+		rows := make(map[string]interface{})
+		for u, r := range new[name] {
+			o, ok := old[name][u]
+			switch {
+			case !ok:
+				if mt.insert {
+					rows[string(u)] = map[string]interface{}{"new": mt.rowJSON(r)}
+				}
+			case o != r && mt.modify:
+				changed := make(map[string]interface{})
+				for _, column := range mt.columns {
+					if !o.fields[column].equal(r.fields[column]) {
+						cs := mt.schema.columns[column]
+						changed[column] = cs.typ.datumToJSON(o.fields[column])
+					}
+				}
+				if len(changed) > 0 {
+					rows[string(u)] = map[string]interface{}{"old": changed, "new": mt.rowJSON(r)}
+				}
+			}
+		}
+		if mt.delete {
+			for u, o := range old[name] {
+				if _, ok := new[name][u]; !ok {
+					rows[string(u)] = map[string]interface{}{"old": mt.rowJSON(o)}
+				}
+			}
+		}
+		if len(rows) > 0 {
+			updates[name] = rows
+		}
+	}
+	return updates
+}
@@ -0,0 +1,271 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// atomic types of RFC 7047
+const (
+	typeInteger string = "integer"
+	typeReal    string = "real"
+	typeBoolean string = "boolean"
+	typeString  string = "string"
+	typeUUID    string = "uuid"
+)
+
+const (
+	refStrong string = "strong"
+	refWeak   string = "weak"
+)
+
+// baseType is the type of the keys or values of a column with its
+// constraints
+type baseType struct {
+	atomic string
+	// enum holds the allowed atoms, nil when any is
+	enum       []interface{}
+	minInteger int64
+	maxInteger int64
+	minReal    float64
+	maxReal    float64
+	minLength  int
+	maxLength  int
+	refTable   string
+	refType    string
+}
+
+// columnType is a set of min to max keys, or a map when value is set.
+// max is -1 for unlimited.
+type columnType struct {
+	key   baseType
+	value *baseType
+	min   int
+	max   int
+}
+
+func (t *columnType) isMap() bool {
+	return t.value != nil
+}
+
+type columnSchema struct {
+	name    string
+	typ     columnType
+	mutable bool
+}
+
+type tableSchema struct {
+	name    string
+	columns map[string]*columnSchema
+	indexes [][]string
+	isRoot  bool
+	maxRows int
+}
+
+type dbSchema struct {
+	name    string
+	version string
+	tables  map[string]*tableSchema
+	// raw is the schema as given, returned by get_schema
+	raw interface{}
+}
+
+// uuidType is the type of the _uuid and _version columns
+var uuidType = columnType{key: baseType{atomic: typeUUID}, min: 1, max: 1}
+
+// column returns the schema of column, including _uuid and _version
+func (ts *tableSchema) column(name string) (*columnSchema, bool) {
+	if name == "_uuid" || name == "_version" {
+		return &columnSchema{name: name, typ: uuidType}, true
+	}
+	c, ok := ts.columns[name]
+	return c, ok
+}
+
+func parseSchema(schema string) (*dbSchema, error) {
+	var raw interface{}
+	err := json.Unmarshal([]byte(schema), &raw)
+	if err != nil {
+		return nil, err
+	}
+	var s struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Tables  map[string]struct {
+			Columns map[string]struct {
+				Type    interface{} `json:"type"`
+				Mutable *bool       `json:"mutable"`
+			} `json:"columns"`
+			Indexes [][]string `json:"indexes"`
+			IsRoot  bool       `json:"isRoot"`
+			MaxRows int        `json:"maxRows"`
+		} `json:"tables"`
+	}
+	err = json.Unmarshal([]byte(schema), &s)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &dbSchema{
+		name:    s.Name,
+		version: s.Version,
+		tables:  make(map[string]*tableSchema),
+		raw:     raw,
+	}
+	anyRoot := false
+	for name, t := range s.Tables {
+		ts := &tableSchema{
+			name:    name,
+			columns: make(map[string]*columnSchema),
+			indexes: t.Indexes,
+			isRoot:  t.IsRoot,
+			maxRows: t.MaxRows,
+		}
+		anyRoot = anyRoot || t.IsRoot
+		for cname, c := range t.Columns {
+			typ, err := parseColumnType(c.Type)
+			if err != nil {
+				return nil, fmt.Errorf("column %s of table %s: %v", cname, name, err)
+			}
+			cs := &columnSchema{name: cname, typ: *typ, mutable: true}
+			if c.Mutable != nil {
+				cs.mutable = *c.Mutable
+			}
+			ts.columns[cname] = cs
+		}
+		db.tables[name] = ts
+	}
+	// schemas predating isRoot have all their tables as roots
+	if !anyRoot {
+		for _, ts := range db.tables {
+			ts.isRoot = true
+		}
+	}
+	return db, nil
+}
+
+func parseColumnType(v interface{}) (*columnType, error) {
+	if atomic, ok := v.(string); ok {
+		key, err := parseBaseType(atomic)
+		if err != nil {
+			return nil, err
+		}
+		return &columnType{key: *key, min: 1, max: 1}, nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid type %v", v)
+	}
+	key, err := parseBaseType(obj["key"])
+	if err != nil {
+		return nil, err
+	}
+	t := &columnType{key: *key, min: 1, max: 1}
+	if value, ok := obj["value"]; ok {
+		t.value, err = parseBaseType(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if min, ok := obj["min"].(float64); ok {
+		t.min = int(min)
+	}
+	switch max := obj["max"].(type) {
+	case float64:
+		t.max = int(max)
+	case string:
+		if max != "unlimited" {
+			return nil, fmt.Errorf("invalid max %s", max)
+		}
+		t.max = -1
+	}
+	return t, nil
+}
+
+func parseBaseType(v interface{}) (*baseType, error) {
+	b := &baseType{
+		minInteger: math.MinInt64,
+		maxInteger: math.MaxInt64,
+		minReal:    -math.MaxFloat64,
+		maxReal:    math.MaxFloat64,
+		maxLength:  math.MaxInt32,
+		refType:    refStrong,
+	}
+	if atomic, ok := v.(string); ok {
+		b.atomic = atomic
+		return b, b.check()
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid base type %v", v)
+	}
+	b.atomic, _ = obj["type"].(string)
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	if n, ok := obj["minInteger"].(float64); ok {
+		b.minInteger = int64(n)
+	}
+	if n, ok := obj["maxInteger"].(float64); ok {
+		b.maxInteger = int64(n)
+	}
+	if n, ok := obj["minReal"].(float64); ok {
+		b.minReal = n
+	}
+	if n, ok := obj["maxReal"].(float64); ok {
+		b.maxReal = n
+	}
+	if n, ok := obj["minLength"].(float64); ok {
+		b.minLength = int(n)
+	}
+	if n, ok := obj["maxLength"].(float64); ok {
+		b.maxLength = int(n)
+	}
+	if refTable, ok := obj["refTable"].(string); ok {
+		b.refTable = refTable
+	}
+	if refType, ok := obj["refType"].(string); ok {
+		b.refType = refType
+	}
+	if enum, ok := obj["enum"]; ok {
+		// the enum is a set of atoms of the base type itself
+		atoms, err := jsonSetElements(enum)
+		if err != nil {
+			return nil, err
+		}
+		plain := *b
+		for _, a := range atoms {
+			atom, err := plain.parseAtom(a, nil)
+			if err != nil {
+				return nil, err
+			}
+			b.enum = append(b.enum, atom)
+		}
+	}
+	return b, nil
+}
+
+func (b *baseType) check() error {
+	switch b.atomic {
+	case typeInteger, typeReal, typeBoolean, typeString, typeUUID:
+		return nil
+	}
+	return fmt.Errorf("unknown atomic type %q", b.atomic)
+}
@@ -0,0 +1,404 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package ovsdbtest provides an in-memory ovsdb-server for tests.
+//
+// The server speaks the JSON-RPC protocol of RFC 7047 over any net.Conn,
+// a unix socket or net.Pipe, so clients are tested without a running
+// ovsdb-server. It implements list_dbs, get_schema, echo, transact with
+// every operation but assert, monitor and monitor_cancel. Transactions are
+// atomic and enforce the schema: types and constraints of the columns,
+// references between tables with garbage collection of the rows of
+// non-root tables, and indexes. Update notifications of a transaction are
+// sent before its reply. Every connection has its own queue of messages, a
+// client not reading holds up no other.
+//
+// Databases are not persisted, neither clustering nor locks are supported.
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Server is an in-memory ovsdb-server
+type Server struct {
+	mu        sync.Mutex
+	dbs       map[string]*database
+	names     []string
+	conns     map[*conn]struct{}
+	listeners []net.Listener
+	// changed is closed and replaced at every commit, for pending waits
+	changed chan struct{}
+	stop    chan struct{}
+	closed  bool
+}
+
+// NewServer returns a server of empty databases of the given schemas, in
+// the JSON format of RFC 7047.
+func NewServer(schemas ...string) (*Server, error) {
+	s := &Server{
+		dbs:     make(map[string]*database),
+		conns:   make(map[*conn]struct{}),
+		changed: make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+	for _, schema := range schemas {
+		parsed, err := parseSchema(schema)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.dbs[parsed.name]; ok {
+			return nil, fmt.Errorf("database %s given twice", parsed.name)
+		}
+		s.dbs[parsed.name] = newDatabase(parsed)
+		s.names = append(s.names, parsed.name)
+	}
+	return s, nil
+}
+
+// NewNBServer returns a server of an empty OVN_Northbound database
+func NewNBServer() (*Server, error) {
+	return NewServer(NBSchema)
+}
+
+// NewSBServer returns a server of an empty OVN_Southbound database
+func NewSBServer() (*Server, error) {
+	return NewServer(SBSchema)
+}
+
+// Serve accepts connections on l until it is closed or the server is
+// closed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return fmt.Errorf("server closed")
+	}
+	s.listeners = append(s.listeners, l)
+	s.mu.Unlock()
+
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go s.ServeConn(nc)
+	}
+}
+
+// ListenUnix serves the unix socket path in the background, until the
+// server is closed. A stale socket file is removed first.
+func (s *Server) ListenUnix(path string) error {
+	os.Remove(path)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	go s.Serve(l)
+	return nil
+}
+
+// Pipe returns the client end of an in-memory connection to the server
+func (s *Server) Pipe() net.Conn {
+	client, server := net.Pipe()
+	go s.ServeConn(server)
+	return client
+}
+
+// ServeConn serves the connection until the client or the server closes it
+func (s *Server) ServeConn(nc net.Conn) {
+	c := &conn{
+		server:   s,
+		nc:       nc,
+		monitors: make(map[string]*monitor),
+		queued:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		nc.Close()
+		return
+	}
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+
+	go c.writeQueue()
+	c.serve()
+
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+	close(c.done)
+	nc.Close()
+}
+
+// Close stops the listeners and closes every connection
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.stop)
+	for _, l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.nc.Close()
+	}
+	return nil
+}
+
+// CloseConnections closes the connection of every client but keeps
+// serving, as a server restart seen by the clients
+func (s *Server) CloseConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.nc.Close()
+	}
+}
+
+// commit makes tables the content of db and notifies the monitors. Caller
+// must hold mu.
+func (s *Server) commit(db *database, tables map[string]map[uuid]*row) {
+	old := db.tables
+	db.tables = tables
+	for c := range s.conns {
+		for _, m := range c.monitors {
+			if m.db != db.schema.name {
+				continue
+			}
+			updates := m.updates(old, tables)
+			if len(updates) > 0 {
+				c.notify("update", []interface{}{m.id, updates})
+			}
+		}
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// conn is a client connection
+type conn struct {
+	server   *Server
+	nc       net.Conn
+	monitors map[string]*monitor
+
+	// queue holds the messages to write, queued is signaled when it is
+	// filled and done closed when the connection is served no more
+	qmu    sync.Mutex
+	queue  []interface{}
+	queued chan struct{}
+	done   chan struct{}
+}
+
+type request struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+	ID     interface{}   `json:"id"`
+}
+
+func (c *conn) serve() {
+	dec := json.NewDecoder(c.nc)
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		if req.Method == "" {
+			// a reply, the server sends no request expecting one
+			continue
+		}
+		if req.Method == "echo" {
+			if req.ID != nil {
+				c.reply(req.ID, req.Params, nil)
+			}
+			continue
+		}
+
+		// the reply is queued with the lock held, after the notifications
+		// of the commits
+		c.server.mu.Lock()
+		result, err := c.handle(req.Method, req.Params)
+		if req.ID != nil {
+			c.reply(req.ID, result, err)
+		}
+		c.server.mu.Unlock()
+	}
+}
+
+// handle runs a request. Caller must hold the server lock.
+func (c *conn) handle(method string, params []interface{}) (interface{}, *ovsdbError) {
+	switch method {
+	case "list_dbs":
+		return c.server.names, nil
+	case "get_schema":
+		db, err := c.database(params)
+		if err != nil {
+			return nil, err
+		}
+		return db.schema.raw, nil
+	case "transact":
+		return c.transact(params)
+	case "monitor":
+		return c.monitor(params)
+	case "monitor_cancel":
+		if len(params) != 1 {
+			return nil, newError("syntax error", "monitor_cancel takes the monitor id")
+		}
+		key := monitorKey(params[0])
+		if _, ok := c.monitors[key]; !ok {
+			return nil, newError("unknown monitor", "no monitor %v", params[0])
+		}
+		delete(c.monitors, key)
+		return map[string]interface{}{}, nil
+	}
+	return nil, newError("unknown method", "method %s is not supported", method)
+}
+
+func (c *conn) database(params []interface{}) (*database, *ovsdbError) {
+	if len(params) == 0 {
+		return nil, newError("syntax error", "missing database name")
+	}
+	name, _ := params[0].(string)
+	db, ok := c.server.dbs[name]
+	if !ok {
+		return nil, newError("unknown database", "database %s is not served", name)
+	}
+	return db, nil
+}
+
+// transact runs the transaction, waiting without the server lock while a
+// wait operation is pending
+func (c *conn) transact(params []interface{}) (interface{}, *ovsdbError) {
+	db, err := c.database(params)
+	if err != nil {
+		return nil, err
+	}
+	s := c.server
+	started := time.Now()
+	for {
+		results, tables, deadline := db.transact(params[1:], started, time.Now())
+		if deadline.IsZero() {
+			if tables != nil {
+				s.commit(db, tables)
+			}
+			return results, nil
+		}
+
+		changed := s.changed
+		s.mu.Unlock()
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case <-changed:
+		case <-timer.C:
+		case <-s.stop:
+		}
+		timer.Stop()
+		s.mu.Lock()
+		if s.closed {
+			return nil, newError("server closed", "the server was closed during the transaction")
+		}
+	}
+}
+
+func monitorKey(id interface{}) string {
+	b, _ := json.Marshal(id)
+	return string(b)
+}
+
+func (c *conn) monitor(params []interface{}) (interface{}, *ovsdbError) {
+	db, err := c.database(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) != 3 {
+		return nil, newError("syntax error", "monitor takes the database, the monitor id and the requests")
+	}
+	key := monitorKey(params[1])
+	if _, ok := c.monitors[key]; ok {
+		return nil, newError("duplicate monitor ID", "monitor %v already exists", params[1])
+	}
+	tables, err := parseMonitorRequests(db.schema, params[2])
+	if err != nil {
+		return nil, err
+	}
+	m := &monitor{db: db.schema.name, id: params[1], tables: tables}
+	c.monitors[key] = m
+	return m.initial(db), nil
+}
+
+// send queues msg for writing, it never waits for the client
+func (c *conn) send(msg interface{}) {
+	c.qmu.Lock()
+	c.queue = append(c.queue, msg)
+	c.qmu.Unlock()
+	select {
+	case c.queued <- struct{}{}:
+	default:
+	}
+}
+
+// writeQueue writes the queued messages in order until the connection is
+// done or fails
+func (c *conn) writeQueue() {
+	enc := json.NewEncoder(c.nc)
+	for {
+		select {
+		case <-c.queued:
+		case <-c.done:
+			return
+		}
+		c.qmu.Lock()
+		msgs := c.queue
+		c.queue = nil
+		c.qmu.Unlock()
+		for _, msg := range msgs {
+			if err := enc.Encode(msg); err != nil {
+				c.nc.Close()
+				return
+			}
+		}
+	}
+}
+
+func (c *conn) reply(id interface{}, result interface{}, err *ovsdbError) {
+	msg := map[string]interface{}{"id": id, "result": result, "error": nil}
+	if err != nil {
+		msg["result"] = nil
+		msg["error"] = err.toJSON()
+	}
+	c.send(msg)
+}
+
+func (c *conn) notify(method string, params []interface{}) {
+	c.send(map[string]interface{}{"id": nil, "method": method, "params": params})
+}
@@ -0,0 +1,312 @@
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClient is a minimal JSON-RPC client of the server
+type testClient struct {
+	t       *testing.T
+	enc     *json.Encoder
+	dec     *json.Decoder
+	id      int
+	updates []interface{}
+}
+
+func newTestClient(t *testing.T, s *Server) *testClient {
+	nc := s.Pipe()
+	return &testClient{t: t, enc: json.NewEncoder(nc), dec: json.NewDecoder(nc)}
+}
+
+func (c *testClient) send(method string, params ...interface{}) int {
+	c.id++
+	err := c.enc.Encode(map[string]interface{}{"id": c.id, "method": method, "params": params})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return c.id
+}
+
+// receive returns the reply to id, keeping the notifications read before
+func (c *testClient) receive(id int) (interface{}, interface{}) {
+	for {
+		var msg struct {
+			ID     interface{}   `json:"id"`
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+			Result interface{}   `json:"result"`
+			Error  interface{}   `json:"error"`
+		}
+		if err := c.dec.Decode(&msg); err != nil {
+			c.t.Fatal(err)
+		}
+		if msg.Method == "update" {
+			c.updates = append(c.updates, msg.Params[1])
+			continue
+		}
+		if msg.ID == float64(id) {
+			return msg.Result, msg.Error
+		}
+	}
+}
+
+func (c *testClient) call(method string, params ...interface{}) (interface{}, interface{}) {
+	return c.receive(c.send(method, params...))
+}
+
+// transact returns the results of the operations given as JSON
+func (c *testClient) transact(ops ...string) []interface{} {
+	params := []interface{}{"OVN_Northbound"}
+	for _, op := range ops {
+		var v interface{}
+		if err := json.Unmarshal([]byte(op), &v); err != nil {
+			c.t.Fatal(err)
+		}
+		params = append(params, v)
+	}
+	result, rpcErr := c.call("transact", params...)
+	if rpcErr != nil {
+		c.t.Fatal(rpcErr)
+	}
+	return result.([]interface{})
+}
+
+// txError returns the error of the results, "" when there is none
+func txError(results []interface{}) string {
+	for _, r := range results {
+		if obj, ok := r.(map[string]interface{}); ok && obj["error"] != nil {
+			return obj["error"].(string) + ": " + obj["details"].(string)
+		}
+	}
+	return ""
+}
+
+func selectRows(c *testClient, table string, columns ...string) []interface{} {
+	cols, _ := json.Marshal(columns)
+	results := c.transact(`{"op": "select", "table": "` + table + `", "where": [], "columns": ` + string(cols) + `}`)
+	return results[0].(map[string]interface{})["rows"].([]interface{})
+}
+
+func newNBClient(t *testing.T) (*Server, *testClient) {
+	s, err := NewNBServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, newTestClient(t, s)
+}
+
+func TestSchema(t *testing.T) {
+	s, c := newNBClient(t)
+	defer s.Close()
+
+	dbs, _ := c.call("list_dbs")
+	assert.Equal(t, []interface{}{"OVN_Northbound"}, dbs, "test[%s]", "list_dbs")
+	schema, _ := c.call("get_schema", "OVN_Northbound")
+	assert.Equal(t, "OVN_Northbound", schema.(map[string]interface{})["name"], "test[%s]", "get_schema")
+	_, rpcErr := c.call("get_schema", "Open_vSwitch")
+	assert.Equal(t, "unknown database", rpcErr.(map[string]interface{})["error"], "test[%s]", "unknown database")
+
+	sb, err := NewSBServer()
+	if assert.Nil(t, err, "test[%s]", "southbound schema") {
+		c := newTestClient(t, sb)
+		dbs, _ := c.call("list_dbs")
+		assert.Equal(t, []interface{}{"OVN_Southbound"}, dbs, "test[%s]", "list_dbs of the southbound server")
+		sb.Close()
+	}
+
+	results := c.transact(`{"op": "insert", "table": "Logical_Switch", "row": {"name": "ls1", "unknown": 1}}`)
+	assert.True(t, strings.HasPrefix(txError(results), "unknown column"), "test[%s]: %s", "unknown column", txError(results))
+	results = c.transact(`{"op": "insert", "table": "ACL", "row": {"priority": 40000}}`)
+	assert.True(t, strings.HasPrefix(txError(results), "constraint violation"), "test[%s]: %s", "integer range", txError(results))
+	results = c.transact(`{"op": "insert", "table": "ACL", "row": {"action": "accept"}}`)
+	assert.True(t, strings.HasPrefix(txError(results), "constraint violation"), "test[%s]: %s", "enum", txError(results))
+	results = c.transact(`{"op": "insert", "table": "Logical_Switch", "row": {"name": 1}}`)
+	assert.True(t, strings.HasPrefix(txError(results), "syntax error"), "test[%s]: %s", "type", txError(results))
+}
+
+func TestTransact(t *testing.T) {
+	s, c := newNBClient(t)
+	defer s.Close()
+
+	results := c.transact(
+		`{"op": "insert", "table": "Logical_Switch_Port", "uuid-name": "lsp", "row": {"name": "lsp1", "addresses": "00:00:00:00:00:01"}}`,
+		`{"op": "insert", "table": "Logical_Switch", "row": {"name": "ls1", "ports": ["named-uuid", "lsp"], "external_ids": ["map", [["k", "v"]]]}}`,
+	)
+	assert.Equal(t, "", txError(results), "test[%s]", "insert with named uuid")
+	assert.Len(t, results, 2, "test[%s]", "a result per operation")
+
+	rows := selectRows(c, "Logical_Switch", "name", "ports", "other_config")
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"name":         "ls1",
+		"ports":        results[0].(map[string]interface{})["uuid"],
+		"other_config": []interface{}{"map", []interface{}{}},
+	}}, rows, "test[%s]", "select with defaults")
+
+	results = c.transact(`{"op": "mutate", "table": "Logical_Switch", "where": [["name", "==", "ls1"]], "mutations": [
+		["external_ids", "insert", ["map", [["k", "ignored"], ["k2", "v2"]]]],
+		["other_config", "insert", ["map", [["a", "b"]]]]]}`)
+	assert.Equal(t, float64(1), results[0].(map[string]interface{})["count"], "test[%s]", "mutate count")
+	results = c.transact(`{"op": "mutate", "table": "Logical_Switch", "where": [], "mutations": [
+		["external_ids", "delete", ["set", ["k2"]]], ["other_config", "delete", ["map", [["a", "c"]]]]]}`)
+	assert.Equal(t, "", txError(results), "test[%s]", "mutate delete")
+	rows = selectRows(c, "Logical_Switch", "external_ids", "other_config")
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"external_ids": []interface{}{"map", []interface{}{[]interface{}{"k", "v"}}},
+		"other_config": []interface{}{"map", []interface{}{[]interface{}{"a", "b"}}},
+	}}, rows, "test[%s]", "map mutations")
+
+	results = c.transact(`{"op": "insert", "table": "Meter_Band", "uuid-name": "band", "row": {"action": "drop", "rate": 10}}`,
+		`{"op": "insert", "table": "Meter", "row": {"name": "m1", "unit": "kbps", "bands": ["named-uuid", "band"]}}`,
+		`{"op": "mutate", "table": "Meter_Band", "where": [], "mutations": [["rate", "*=", 3], ["burst_size", "+=", 5]]}`)
+	assert.Equal(t, "", txError(results), "test[%s]", "arithmetic")
+	rows = selectRows(c, "Meter_Band", "rate", "burst_size")
+	assert.Equal(t, []interface{}{map[string]interface{}{"rate": float64(30), "burst_size": float64(5)}}, rows, "test[%s]", "arithmetic result")
+	results = c.transact(`{"op": "mutate", "table": "Meter_Band", "where": [], "mutations": [["rate", "/=", 0]]}`)
+	assert.True(t, strings.HasPrefix(txError(results), "domain error"), "test[%s]: %s", "division by zero", txError(results))
+
+	results = c.transact(`{"op": "insert", "table": "Logical_Switch", "row": {"name": "ls2"}}`, `{"op": "abort"}`)
+	assert.True(t, strings.HasPrefix(txError(results), "aborted"), "test[%s]", "abort")
+	results = c.transact(`{"op": "update", "table": "Logical_Switch", "where": [["name", "==", "ls1"]], "row": {"name": "ls3"}}`,
+		`{"op": "insert", "table": "ACL", "row": {"priority": 99999}}`)
+	assert.Len(t, results, 2, "test[%s]", "results until the failed operation")
+	assert.Nil(t, results[1].(map[string]interface{})["count"], "test[%s]", "failed operation")
+	rows = selectRows(c, "Logical_Switch", "name")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "ls1"}}, rows, "test[%s]", "failed transaction not committed")
+}
+
+func TestReferences(t *testing.T) {
+	s, c := newNBClient(t)
+	defer s.Close()
+
+	results := c.transact(`{"op": "insert", "table": "Logical_Switch", "row": {"name": "ls1", "ports": ["uuid", "2f1c7b1e-0d16-4b5b-a7a6-6c5b5a4d8e01"]}}`)
+	assert.Len(t, results, 2, "test[%s]", "commit error appended")
+	assert.True(t, strings.HasPrefix(txError(results), "referential integrity violation"), "test[%s]: %s", "dangling strong reference", txError(results))
+
+	// a row of a non-root table exists only while referenced
+	c.transact(`{"op": "insert", "table": "Logical_Switch_Port", "row": {"name": "orphan"}}`)
+	assert.Len(t, selectRows(c, "Logical_Switch_Port", "name"), 0, "test[%s]", "orphan collected")
+
+	c.transact(
+		`{"op": "insert", "table": "Logical_Switch_Port", "uuid-name": "lsp", "row": {"name": "lsp1"}}`,
+		`{"op": "insert", "table": "Logical_Switch", "row": {"name": "ls1", "ports": ["named-uuid", "lsp"]}}`,
+		`{"op": "insert", "table": "Port_Group", "row": {"name": "pg1", "ports": ["named-uuid", "lsp"]}}`,
+	)
+	assert.Len(t, selectRows(c, "Logical_Switch_Port", "name"), 1, "test[%s]", "referenced port kept")
+
+	results = c.transact(`{"op": "delete", "table": "Logical_Switch_Port", "where": []}`)
+	assert.True(t, strings.HasPrefix(txError(results), "referential integrity violation"), "test[%s]: %s", "delete of a referenced row", txError(results))
+
+	c.transact(`{"op": "delete", "table": "Logical_Switch", "where": [["name", "==", "ls1"]]}`)
+	assert.Len(t, selectRows(c, "Logical_Switch_Port", "name"), 0, "test[%s]", "ports deleted with the switch")
+	assert.Equal(t, []interface{}{map[string]interface{}{"ports": []interface{}{"set", []interface{}{}}}},
+		selectRows(c, "Port_Group", "ports"), "test[%s]", "weak reference removed")
+}
+
+func TestIndex(t *testing.T) {
+	s, c := newNBClient(t)
+	defer s.Close()
+
+	c.transact(`{"op": "insert", "table": "Address_Set", "row": {"name": "as1"}}`)
+	results := c.transact(`{"op": "insert", "table": "Address_Set", "row": {"name": "as1"}}`)
+	assert.True(t, strings.HasPrefix(txError(results), "constraint violation"), "test[%s]: %s", "index conflict", txError(results))
+	assert.Contains(t, txError(results), "for index on column \"name\"", "test[%s]", "index details")
+
+	// a transaction may swap the values of an index
+	results = c.transact(`{"op": "insert", "table": "Address_Set", "row": {"name": "as2"}}`,
+		`{"op": "update", "table": "Address_Set", "where": [["name", "==", "as1"]], "row": {"name": "as3"}}`)
+	assert.Equal(t, "", txError(results), "test[%s]", "unique after the transaction")
+}
+
+func TestWait(t *testing.T) {
+	s, c := newNBClient(t)
+	defer s.Close()
+
+	results := c.transact(`{"op": "wait", "table": "Logical_Switch", "where": [], "columns": ["name"], "until": "==", "rows": [{"name": "ls1"}], "timeout": 0}`)
+	assert.True(t, strings.HasPrefix(txError(results), "timed out"), "test[%s]: %s", "wait timed out", txError(results))
+
+	// the wait is met by the commit of another client
+	other := newTestClient(t, s)
+	var wait interface{}
+	json.Unmarshal([]byte(`{"op": "wait", "table": "Logical_Switch", "where": [], "columns": ["name"], "until": "==", "rows": [{"name": "ls1"}], "timeout": 10000}`), &wait)
+	id := c.send("transact", "OVN_Northbound", wait)
+	results = other.transact(`{"op": "insert", "table": "Logical_Switch", "row": {"name": "ls1"}}`)
+	assert.Equal(t, "", txError(results), "test[%s]", "insert during wait")
+	result, _ := c.receive(id)
+	assert.Equal(t, "", txError(result.([]interface{})), "test[%s]", "wait met")
+}
+
+func TestMonitor(t *testing.T) {
+	s, c := newNBClient(t)
+	defer s.Close()
+
+	c.transact(`{"op": "insert", "table": "Logical_Switch", "uuid-name": "ls", "row": {"name": "ls1"}}`)
+	initial, rpcErr := c.call("monitor", "OVN_Northbound", "mon", map[string]interface{}{
+		"Logical_Switch": map[string]interface{}{"columns": []string{"name", "external_ids"}},
+	})
+	assert.Nil(t, rpcErr, "test[%s]", "monitor")
+	for _, r := range initial.(map[string]interface{})["Logical_Switch"].(map[string]interface{}) {
+		assert.Equal(t, map[string]interface{}{"new": map[string]interface{}{
+			"name":         "ls1",
+			"external_ids": []interface{}{"map", []interface{}{}},
+		}}, r, "test[%s]", "initial rows")
+	}
+	_, rpcErr = c.call("monitor", "OVN_Northbound", "mon", map[string]interface{}{})
+	assert.NotNil(t, rpcErr, "test[%s]", "duplicate monitor")
+
+	// updates are received before the reply of the transaction
+	c.transact(`{"op": "update", "table": "Logical_Switch", "where": [], "row": {"name": "ls2"}}`)
+	c.transact(`{"op": "update", "table": "Logical_Switch", "where": [], "row": {"other_config": ["map", [["a", "b"]]]}}`)
+	c.transact(`{"op": "delete", "table": "Logical_Switch", "where": []}`)
+	assert.Len(t, c.updates, 2, "test[%s]", "unmonitored column not notified")
+	for _, r := range c.updates[0].(map[string]interface{})["Logical_Switch"].(map[string]interface{}) {
+		assert.Equal(t, map[string]interface{}{
+			"old": map[string]interface{}{"name": "ls1"},
+			"new": map[string]interface{}{"name": "ls2", "external_ids": []interface{}{"map", []interface{}{}}},
+		}, r, "test[%s]", "modify")
+	}
+	for _, r := range c.updates[1].(map[string]interface{})["Logical_Switch"].(map[string]interface{}) {
+		assert.Equal(t, map[string]interface{}{
+			"old": map[string]interface{}{"name": "ls2", "external_ids": []interface{}{"map", []interface{}{}}},
+		}, r, "test[%s]", "delete")
+	}
+
+	_, rpcErr = c.call("monitor_cancel", "mon")
+	assert.Nil(t, rpcErr, "test[%s]", "monitor_cancel")
+	c.transact(`{"op": "insert", "table": "Logical_Switch", "row": {"name": "ls3"}}`)
+	assert.Len(t, c.updates, 2, "test[%s]", "no update after cancel")
+}
+
+func TestSlowClient(t *testing.T) {
+	s, c := newNBClient(t)
+	defer s.Close()
+
+	// a client monitoring but not reading
+	nc := s.Pipe()
+	slow := &testClient{t: t, enc: json.NewEncoder(nc), dec: json.NewDecoder(nc)}
+	_, rpcErr := slow.call("monitor", "OVN_Northbound", "mon", map[string]interface{}{
+		"Logical_Switch": map[string]interface{}{"columns": []string{"name"}},
+	})
+	assert.Nil(t, rpcErr, "test[%s]", "monitor")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, name := range []string{"ls1", "ls2", "ls3"} {
+			c.transact(`{"op": "insert", "table": "Logical_Switch", "row": {"name": "` + name + `"}}`)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("transactions held up by a client not reading")
+	}
+
+	// the slow client gets the queued updates once it reads
+	slow.call("echo")
+	assert.Len(t, slow.updates, 3, "test[%s]", "queued updates")
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"github.com/unistack-org/libovsdb"
+)
+
+type PortBinding struct {
+	UUID        string                      `ovsdb:"_uuid"`
+	LogicalPort string                      `ovsdb:"logical_port"`
+	Type        string                      `ovsdb:"type"`
+	Datapath    string                      `ovsdb:"datapath"`
+	TunnelKey   int                         `ovsdb:"tunnel_key"`
+	ParentPort  string                      `ovsdb:"parent_port"`
+	Tag         int                         `ovsdb:"tag"`
+	Chassis     string                      `ovsdb:"chassis"`
+	MAC         []string                    `ovsdb:"mac"`
+	Options     map[interface{}]interface{} `ovsdb:"options"`
+	ExternalID  map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+func (odbi *ovnDBImp) RowToPortBinding(uuid string) (*PortBinding, error) {
+	pb := &PortBinding{}
+	if err := decodeRow(uuid, odbi.cache[tablePortBinding][uuid].Fields, pb); err != nil {
+		return nil, err
+	}
+	return pb, nil
+}
+
+// Get all port bindings
+func (odbi *ovnDBImp) GetPortBindings() []*PortBinding {
+	var pblist = []*PortBinding{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid := range odbi.cache[tablePortBinding] {
+		if pb, err := odbi.RowToPortBinding(uuid); err == nil {
+			pblist = append(pblist, pb)
+		}
+	}
+	return pblist
+}
+
+// Get port binding by logical port name
+func (odbi *ovnDBImp) GetPortBindingByLogicalPort(lport string) (*PortBinding, error) {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid, drows := range odbi.cache[tablePortBinding] {
+		if lp, ok := drows.Fields["logical_port"].(string); ok && lp == lport {
+			return odbi.RowToPortBinding(uuid)
+		}
+	}
+	return nil, ErrorNotFound
+}
+
+// Get all port bindings bound to chassis with given name
+func (odbi *ovnDBImp) GetPortBindingsByChassis(name string) ([]*PortBinding, error) {
+	var pblist = []*PortBinding{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	chassisUUID := ""
+	for uuid, drows := range odbi.cache[tableChassis] {
+		if chName, ok := drows.Fields["name"].(string); ok && chName == name {
+			chassisUUID = uuid
+			break
+		}
+	}
+	if chassisUUID == "" {
+		return nil, ErrorNotFound
+	}
+	for uuid, drows := range odbi.cache[tablePortBinding] {
+		if chassis, ok := drows.Fields["chassis"].(libovsdb.UUID); ok && chassis.GoUUID == chassisUUID {
+			if pb, err := odbi.RowToPortBinding(uuid); err == nil {
+				pblist = append(pblist, pb)
+			}
+		}
+	}
+	return pblist, nil
+}
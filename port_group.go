@@ -0,0 +1,178 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"github.com/unistack-org/libovsdb"
+)
+
+type PortGroup struct {
+	UUID       string                      `ovsdb:"_uuid"`
+	Name       string                      `ovsdb:"name"`
+	Ports      []string                    `ovsdb:"ports"`
+	ACLs       []string                    `ovsdb:"acls"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+func newPortGroupPorts(ports []string) (*libovsdb.OvsSet, error) {
+	portUUIDs := make([]libovsdb.UUID, 0, len(ports))
+	for _, port := range ports {
+		portUUIDs = append(portUUIDs, libovsdb.UUID{GoUUID: port})
+	}
+	return libovsdb.NewOvsSet(portUUIDs)
+}
+
+func (odbi *ovnDBImp) pgAddImp(group string, ports []string, external_ids map[string]string) (*OvnCommand, error) {
+	namedUUID, err := newRowUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(OVNRow)
+	row["name"] = group
+
+	if uuid := odbi.getRowUUID(tablePortGroup, row); len(uuid) > 0 {
+		return nil, ErrorExist
+	}
+
+	row, err = odbi.encodeRow(tablePortGroup, &PortGroup{
+		Name:       group,
+		Ports:      ports,
+		ExternalID: toModelMap(external_ids),
+	}, "name", "ports", "external_ids")
+	if err != nil {
+		return nil, err
+	}
+
+	insertOp := libovsdb.Operation{
+		Op:       opInsert,
+		Table:    tablePortGroup,
+		Row:      row,
+		UUIDName: namedUUID,
+	}
+	operations := []libovsdb.Operation{insertOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) pgUpdateImp(group string, ports []string, external_ids map[string]string) (*OvnCommand, error) {
+	row := make(OVNRow)
+	row["name"] = group
+
+	if uuid := odbi.getRowUUID(tablePortGroup, row); len(uuid) == 0 {
+		return nil, ErrorNotFound
+	}
+
+	columns := []string{"name", "ports"}
+	if external_ids != nil {
+		columns = append(columns, "external_ids")
+	}
+	row, err := odbi.encodeRow(tablePortGroup, &PortGroup{
+		Name:       group,
+		Ports:      ports,
+		ExternalID: toModelMap(external_ids),
+	}, columns...)
+	if err != nil {
+		return nil, err
+	}
+
+	condition := libovsdb.NewCondition("name", "==", group)
+	updateOp := libovsdb.Operation{
+		Op:    opUpdate,
+		Table: tablePortGroup,
+		Row:   row,
+		Where: []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{updateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) pgDelImp(group string) (*OvnCommand, error) {
+	condition := libovsdb.NewCondition("name", "==", group)
+	deleteOp := libovsdb.Operation{
+		Op:    opDelete,
+		Table: tablePortGroup,
+		Where: []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{deleteOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) pgMutatePortImp(group, port, mutator string) (*OvnCommand, error) {
+	row := make(OVNRow)
+	row["name"] = group
+
+	if uuid := odbi.getRowUUID(tablePortGroup, row); len(uuid) == 0 {
+		return nil, ErrorNotFound
+	}
+
+	mutateSet, err := newPortGroupPorts([]string{port})
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("ports", mutator, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", group)
+
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tablePortGroup,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{mutateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) pgAddPortImp(group, port string) (*OvnCommand, error) {
+	return odbi.pgMutatePortImp(group, port, opInsert)
+}
+
+func (odbi *ovnDBImp) pgRemovePortImp(group, port string) (*OvnCommand, error) {
+	return odbi.pgMutatePortImp(group, port, opDelete)
+}
+
+func (odbi *ovnDBImp) RowToPortGroup(uuid string) (*PortGroup, error) {
+	pg := &PortGroup{}
+	if err := decodeRow(uuid, odbi.cache[tablePortGroup][uuid].Fields, pg); err != nil {
+		return nil, err
+	}
+	return pg, nil
+}
+
+// Get port group by name
+func (odbi *ovnDBImp) GetPortGroupByName(group string) (*PortGroup, error) {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid, drows := range odbi.cache[tablePortGroup] {
+		if pgName, ok := drows.Fields["name"].(string); ok && pgName == group {
+			return odbi.RowToPortGroup(uuid)
+		}
+	}
+	return nil, ErrorNotFound
+}
+
+// Get all port groups
+func (odbi *ovnDBImp) GetPortGroups() []*PortGroup {
+	var pglist = []*PortGroup{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	for uuid := range odbi.cache[tablePortGroup] {
+		if pg, err := odbi.RowToPortGroup(uuid); err == nil {
+			pglist = append(pglist, pg)
+		}
+	}
+	return pglist
+}
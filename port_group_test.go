@@ -0,0 +1,163 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	PG       = "TEST_PG"
+	PG_MATCH = "outport == @TEST_PG && ip4"
+)
+
+func TestPortGroup(t *testing.T) {
+	var cmds []*OvnCommand
+	var cmd *OvnCommand
+	var err error
+
+	cmds = make([]*OvnCommand, 0)
+	cmd, err = ovndbapi.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LSPAdd(LSW, LSP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	cmd, err = ovndbapi.LSPAdd(LSW, LSP_SECOND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds = append(cmds, cmd)
+
+	err = ovndbapi.Execute(cmds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lsps, err := ovndbapi.GetLogicPortsBySwitch(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lsps) != 2 {
+		t.Fatalf("lsps not created %v", lsps)
+	}
+
+	cmd, err = ovndbapi.PGAdd(PG, []string{lsps[0].UUID}, map[string]string{"owner": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.PGAdd(PG, nil, nil)
+	assert.Equal(t, ErrorExist, err, "test[%s]", "add same port group twice")
+
+	pg, err := ovndbapi.GetPortGroupByName(PG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(pg.Ports) == 1 && pg.Ports[0] == lsps[0].UUID, "test[%s]: %v", "port group created", pg)
+
+	cmd, err = ovndbapi.PGAddPort(PG, lsps[1].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pg, err = ovndbapi.GetPortGroupByName(PG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(pg.Ports) == 2, "test[%s]: %v", "port added to port group", pg)
+
+	cmd, err = ovndbapi.PGRemovePort(PG, lsps[0].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pg, err = ovndbapi.GetPortGroupByName(PG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(pg.Ports) == 1 && pg.Ports[0] == lsps[1].UUID, "test[%s]: %v", "port removed from port group", pg)
+
+	cmd, err = ovndbapi.PGUpdate(PG, []string{lsps[0].UUID, lsps[1].UUID}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pg, err = ovndbapi.GetPortGroupByName(PG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(pg.Ports) == 2, "test[%s]: %v", "port group updated", pg)
+
+	cmd, err = ovndbapi.PGACLAdd(PG, "to-lport", PG_MATCH, "allow-related", 1001, nil, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acls := ovndbapi.GetACLsByPortGroup(PG)
+	assert.Equal(t, true, len(acls) == 1 && acls[0].Match == PG_MATCH &&
+		acls[0].Action == "allow-related" && acls[0].Priority == 1001, "test[%s] %v", "add port group acl", acls)
+	assert.Equal(t, true, len(ovndbapi.GetACLsBySwitch(LSW)) == 0, "test[%s]", "port group acl not on switch")
+
+	_, err = ovndbapi.PGACLAdd(PG, "to-lport", PG_MATCH, "allow-related", 1001, nil, false, "")
+	assert.Equal(t, ErrorExist, err, "test[%s]", "add same port group acl twice")
+
+	cmd, err = ovndbapi.PGACLDel(PG, "to-lport", PG_MATCH, 1001, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acls = ovndbapi.GetACLsByPortGroup(PG)
+	assert.Equal(t, true, len(acls) == 0, "test[%s]", "port group acl remove")
+
+	cmd, err = ovndbapi.PGDel(PG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.GetPortGroupByName(PG)
+	assert.Equal(t, ErrorNotFound, err, "test[%s]", "port group remove")
+
+	cmd, err = ovndbapi.LSWDel(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
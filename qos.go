@@ -0,0 +1,181 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"github.com/unistack-org/libovsdb"
+)
+
+type QoS struct {
+	UUID       string                      `ovsdb:"_uuid"`
+	Priority   int                         `ovsdb:"priority"`
+	Direction  string                      `ovsdb:"direction"`
+	Match      string                      `ovsdb:"match"`
+	Action     map[string]int              `ovsdb:"action"`
+	Bandwidth  map[string]int              `ovsdb:"bandwidth"`
+	ExternalID map[interface{}]interface{} `ovsdb:"external_ids"`
+}
+
+// qosMatches reports whether the cached qos rule matches direction, priority
+// and match. Empty direction or match and negative priority match anything.
+func (odbi *ovnDBImp) qosMatches(uuid string, direction string, priority int, match string) bool {
+	qos := odbi.cache[tableQoS][uuid]
+	if direction != "" && qos.Fields["direction"] != direction {
+		return false
+	}
+	if priority >= 0 && qos.Fields["priority"] != priority {
+		return false
+	}
+	if match != "" && qos.Fields["match"] != match {
+		return false
+	}
+	return true
+}
+
+func (odbi *ovnDBImp) qosAddImp(lsw string, direction string, priority int, match string, action map[string]int, bandwidth map[string]int, external_ids map[string]string) (*OvnCommand, error) {
+	odbi.cachemutex.RLock()
+	_, rules, err := odbi.getRowRefsByName(tableLogicalSwitch, lsw, "qos_rules")
+	if err == nil {
+		for _, rule := range rules {
+			if odbi.qosMatches(rule, direction, priority, match) {
+				err = ErrorExist
+				break
+			}
+		}
+	}
+	odbi.cachemutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	namedUUID, err := newRowUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := odbi.encodeRow(tableQoS, &QoS{
+		Priority:   priority,
+		Direction:  direction,
+		Match:      match,
+		Action:     action,
+		Bandwidth:  bandwidth,
+		ExternalID: toModelMap(external_ids),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	insertOp := libovsdb.Operation{
+		Op:       opInsert,
+		Table:    tableQoS,
+		Row:      row,
+		UUIDName: namedUUID,
+	}
+
+	mutateUUID := []libovsdb.UUID{{GoUUID: namedUUID}}
+	mutateSet, err := libovsdb.NewOvsSet(mutateUUID)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("qos_rules", opInsert, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", lsw)
+
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalSwitch,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+	operations := []libovsdb.Operation{insertOp, mutateOp}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+// qosDelImp deletes qos rules of lsw selected by direction, priority and
+// match, see qosMatches.
+func (odbi *ovnDBImp) qosDelImp(lsw string, direction string, priority int, match string) (*OvnCommand, error) {
+	odbi.cachemutex.RLock()
+	var qosUUIDs []libovsdb.UUID
+	lswUUID, rules, err := odbi.getRowRefsByName(tableLogicalSwitch, lsw, "qos_rules")
+	if err == nil {
+		for _, rule := range rules {
+			if odbi.qosMatches(rule, direction, priority, match) {
+				qosUUIDs = append(qosUUIDs, libovsdb.UUID{GoUUID: rule})
+			}
+		}
+	}
+	odbi.cachemutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(qosUUIDs) == 0 {
+		return nil, ErrorNotFound
+	}
+
+	mutateSet, err := libovsdb.NewOvsSet(qosUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	mutation := libovsdb.NewMutation("qos_rules", opDelete, mutateSet)
+	mucondition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: lswUUID})
+
+	// simple mutate operation
+	mutateOp := libovsdb.Operation{
+		Op:        opMutate,
+		Table:     tableLogicalSwitch,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{mucondition},
+	}
+	operations := []libovsdb.Operation{mutateOp}
+
+	for _, qosUUID := range qosUUIDs {
+		condition := libovsdb.NewCondition("_uuid", "==", qosUUID)
+		deleteOp := libovsdb.Operation{
+			Op:    opDelete,
+			Table: tableQoS,
+			Where: []interface{}{condition},
+		}
+		operations = append(operations, deleteOp)
+	}
+	return &OvnCommand{operations, odbi, make([][]map[string]interface{}, len(operations))}, nil
+}
+
+func (odbi *ovnDBImp) RowToQoS(uuid string) (*QoS, error) {
+	qos := &QoS{}
+	if err := decodeRow(uuid, odbi.cache[tableQoS][uuid].Fields, qos); err != nil {
+		return nil, err
+	}
+	return qos, nil
+}
+
+// Get all qos rules by lswitch
+func (odbi *ovnDBImp) GetQoSBySwitch(lsw string) ([]*QoS, error) {
+	var qoslist = []*QoS{}
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	_, rules, err := odbi.getRowRefsByName(tableLogicalSwitch, lsw, "qos_rules")
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if _, ok := odbi.cache[tableQoS][rule]; ok {
+			if qos, err := odbi.RowToQoS(rule); err == nil {
+				qoslist = append(qoslist, qos)
+			}
+		}
+	}
+	return qoslist, nil
+}
@@ -0,0 +1,99 @@
+package goovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	QOS_MATCH = "inport == \"TEST_LSP\" && ip4"
+)
+
+func TestQoS(t *testing.T) {
+	var cmd *OvnCommand
+	var err error
+
+	cmd, err = ovndbapi.LSWAdd(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.QoSAdd(LSW, "from-lport", 1001, QOS_MATCH, map[string]int{"dscp": 46}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err = ovndbapi.QoSAdd(LSW, "from-lport", 1002, QOS_MATCH, nil, map[string]int{"rate": 10000, "burst": 1000}, map[string]string{"A": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ovndbapi.QoSAdd(LSW, "from-lport", 1001, QOS_MATCH, map[string]int{"dscp": 10}, nil, nil)
+	assert.Equal(t, ErrorExist, err, "test[%s]", "add same qos rule twice")
+
+	qosrules, err := ovndbapi.GetQoSBySwitch(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(qosrules) == 2, "test[%s]: %v", "added qos rules", qosrules)
+	for _, qos := range qosrules {
+		switch qos.Priority {
+		case 1001:
+			assert.Equal(t, map[string]int{"dscp": 46}, qos.Action, "test[%s]", "dscp marking")
+		case 1002:
+			assert.Equal(t, map[string]int{"rate": 10000, "burst": 1000}, qos.Bandwidth, "test[%s]", "rate limiting")
+		}
+	}
+
+	cmd, err = ovndbapi.QoSDel(LSW, "from-lport", 1001, QOS_MATCH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qosrules, err = ovndbapi.GetQoSBySwitch(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(qosrules) == 1 && qosrules[0].Priority == 1002, "test[%s]: %v", "qos rule removed", qosrules)
+
+	cmd, err = ovndbapi.QoSDel(LSW, "", -1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qosrules, err = ovndbapi.GetQoSBySwitch(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, len(qosrules) == 0, "test[%s]: %v", "all qos rules removed", qosrules)
+
+	cmd, err = ovndbapi.LSWDel(LSW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ovndbapi.Execute(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,206 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// ConnState is the state of the connection of a client to its database
+type ConnState int
+
+const (
+	ConnStateConnected ConnState = iota
+	ConnStateDisconnected
+	ConnStateClosed
+)
+
+func (state ConnState) String() string {
+	switch state {
+	case ConnStateConnected:
+		return "connected"
+	case ConnStateDisconnected:
+		return "disconnected"
+	case ConnStateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+const (
+	defaultReconnectMinBackoff = time.Second
+	defaultReconnectMaxBackoff = 30 * time.Second
+	// backgroundTimeout bounds the requests the client issues on its own,
+	// when reconnecting and closing
+	backgroundTimeout = 30 * time.Second
+)
+
+func (odbi *ovnDBImp) setState(state ConnState) {
+	if odbi.statecb != nil {
+		odbi.statecb(state)
+	}
+}
+
+func (odbi *ovnDBImp) closed() bool {
+	select {
+	case <-odbi.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// backgroundContext bounds a request by backgroundTimeout and aborts it when
+// the client is closed.
+func (odbi *ovnDBImp) backgroundContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+	go func() {
+		select {
+		case <-odbi.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// reconnect dials the database again after conn was lost, until it succeeds
+// or the client is closed.
+func (odbi *ovnDBImp) reconnect(conn *ovsdbConn) {
+	odbi.lockTransact()
+	stale := conn != odbi.client.dbclient
+	odbi.unlockTransact()
+	if stale || odbi.closed() {
+		return
+	}
+
+	odbi.setState(ConnStateDisconnected)
+	if odbi.noReconnect {
+		return
+	}
+
+	backoff := odbi.minBackoff
+	for {
+		select {
+		case <-odbi.stop:
+			return
+		case <-time.After(backoff):
+		}
+		ctx, cancel := odbi.backgroundContext()
+		err := odbi.resync(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+		backoff *= 2
+		if backoff > odbi.maxBackoff {
+			backoff = odbi.maxBackoff
+		}
+	}
+	if !odbi.closed() {
+		odbi.setState(ConnStateConnected)
+	}
+}
+
+// resync switches to a new connection and brings the cache up to date with
+// it. Transactions wait until the switch is done.
+func (odbi *ovnDBImp) resync(ctx context.Context) error {
+	conn, err := odbi.client.connect(ctx, odbi.db)
+	if err != nil {
+		return err
+	}
+
+	odbi.lockTransact()
+	defer odbi.unlockTransact()
+	if odbi.closed() {
+		conn.Disconnect()
+		return nil
+	}
+
+	old := odbi.client.dbclient
+	odbi.client.dbclient = conn
+	notifier := newOVNNotifier(odbi, conn)
+	conn.Register(notifier)
+	snapshot, err := odbi.monitor(ctx)
+	if err == nil {
+		err = odbi.monitorServer(ctx, conn)
+	}
+	if err != nil {
+		odbi.client.dbclient = old
+		conn.Disconnect()
+		return err
+	}
+	odbi.setSchema(conn)
+	notifier.applySnapshot(odbi.diffCache(*snapshot))
+	return nil
+}
+
+// diffCache turns a full snapshot of the cached tables into the updates that
+// bring the cache to it, so only rows changed while disconnected are
+// signaled.
+func (odbi *ovnDBImp) diffCache(snapshot libovsdb.TableUpdates) libovsdb.TableUpdates {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+
+	updates := libovsdb.TableUpdates{Updates: make(map[string]libovsdb.TableUpdate)}
+	for table, rows := range odbi.cache {
+		tableUpdate := libovsdb.TableUpdate{Rows: make(map[string]libovsdb.RowUpdate)}
+		for uuid, row := range rows {
+			if _, ok := snapshot.Updates[table].Rows[uuid]; !ok {
+				tableUpdate.Rows[uuid] = libovsdb.RowUpdate{Old: row}
+			}
+		}
+		updates.Updates[table] = tableUpdate
+	}
+	for table, snapshotUpdate := range snapshot.Updates {
+		tableUpdate, ok := updates.Updates[table]
+		if !ok {
+			tableUpdate = libovsdb.TableUpdate{Rows: make(map[string]libovsdb.RowUpdate)}
+			updates.Updates[table] = tableUpdate
+		}
+		for uuid, row := range snapshotUpdate.Rows {
+			odbi.float64_to_int(row.New)
+			if old, ok := odbi.cache[table][uuid]; ok && reflect.DeepEqual(old, row.New) {
+				continue
+			}
+			tableUpdate.Rows[uuid] = libovsdb.RowUpdate{New: row.New, Old: odbi.cache[table][uuid]}
+		}
+	}
+	return updates
+}
+
+// close cancels the monitor and disconnects, ending any reconnection.
+func (odbi *ovnDBImp) close() error {
+	odbi.lockTransact()
+	conn := odbi.client.dbclient
+	if !odbi.closed() {
+		close(odbi.stop)
+	}
+	odbi.unlockTransact()
+
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+	defer cancel()
+	err := conn.MonitorCancel(ctx, odbi.db, odbi.db)
+	conn.Disconnect()
+	odbi.unsubscribeAll()
+	odbi.setState(ConnStateClosed)
+	return err
+}
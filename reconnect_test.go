@@ -0,0 +1,220 @@
+package goovn
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ebay/go-ovn/ovsdbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+// lswRecorder records the names of the logical switches it is signaled for
+type lswRecorder struct {
+	created []string
+	deleted []string
+}
+
+func (r *lswRecorder) OnLogicalSwitchCreate(ls *LogicalSwitch) {
+	r.created = append(r.created, ls.Name)
+}
+func (r *lswRecorder) OnLogicalSwitchDelete(ls *LogicalSwitch) {
+	r.deleted = append(r.deleted, ls.Name)
+}
+func (r *lswRecorder) OnLogicalPortCreate(lp *LogicalSwitchPort)        {}
+func (r *lswRecorder) OnLogicalPortDelete(lp *LogicalSwitchPort)        {}
+func (r *lswRecorder) OnLogicalRouterCreate(lr *LogicalRouter)          {}
+func (r *lswRecorder) OnLogicalRouterDelete(lr *LogicalRouter)          {}
+func (r *lswRecorder) OnLogicalRouterPortCreate(lrp *LogicalRouterPort) {}
+func (r *lswRecorder) OnLogicalRouterPortDelete(lrp *LogicalRouterPort) {}
+func (r *lswRecorder) OnACLCreate(acl *ACL)                             {}
+func (r *lswRecorder) OnACLDelete(acl *ACL)                             {}
+func (r *lswRecorder) OnDHCPOptionsCreate(dhcp *DHCPOptions)            {}
+func (r *lswRecorder) OnDHCPOptionsDelete(dhcp *DHCPOptions)            {}
+
+func lswRow(name string) libovsdb.Row {
+	return libovsdb.Row{Fields: map[string]interface{}{
+		"name":         name,
+		"external_ids": libovsdb.OvsMap{GoMap: map[interface{}]interface{}{}},
+	}}
+}
+
+func TestResyncCache(t *testing.T) {
+	recorder := &lswRecorder{}
+	odbi := &ovnDBImp{
+		cache: map[string]map[string]libovsdb.Row{
+			tableLogicalSwitch: {
+				"uuid-kept":    lswRow("kept"),
+				"uuid-renamed": lswRow("old-name"),
+				"uuid-removed": lswRow("removed"),
+			},
+		},
+		callback: recorder,
+	}
+
+	snapshot := libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableLogicalSwitch: {Rows: map[string]libovsdb.RowUpdate{
+			"uuid-kept":    {New: lswRow("kept")},
+			"uuid-renamed": {New: lswRow("new-name")},
+			"uuid-added":   {New: lswRow("added")},
+		}},
+	}}
+	odbi.populateCache(odbi.diffCache(snapshot))
+
+	sort.Strings(recorder.created)
+	assert.Equal(t, []string{"added", "new-name"}, recorder.created, "test[%s]", "only changed rows signaled")
+	assert.Equal(t, []string{"removed"}, recorder.deleted, "test[%s]", "rows gone while disconnected signaled")
+
+	names := []string{}
+	for _, ls := range odbi.GetLogicSwitches() {
+		names = append(names, ls.Name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"added", "kept", "new-name"}, names, "test[%s]", "cache matches snapshot")
+}
+
+func TestPendingUpdates(t *testing.T) {
+	odbi := &ovnDBImp{cache: make(map[string]map[string]libovsdb.Row)}
+	notifier := newOVNNotifier(odbi, nil)
+
+	// an update committed after the monitor request, before its reply is
+	// applied
+	notifier.Update([]interface{}{NBDB}, libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableLogicalSwitch: {Rows: map[string]libovsdb.RowUpdate{
+			"uuid-ls": {New: lswRow("renamed")},
+		}},
+	}})
+	assert.Equal(t, 0, len(odbi.GetLogicSwitches()), "test[%s]", "update held until the snapshot")
+
+	notifier.applySnapshot(libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableLogicalSwitch: {Rows: map[string]libovsdb.RowUpdate{
+			"uuid-ls": {New: lswRow("initial")},
+		}},
+	}})
+	lsws := odbi.GetLogicSwitches()
+	if assert.Equal(t, 1, len(lsws), "test[%s]", "snapshot applied") {
+		assert.Equal(t, "renamed", lsws[0].Name, "test[%s]", "held update applied after the snapshot")
+	}
+
+	notifier.Update([]interface{}{NBDB}, libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		tableLogicalSwitch: {Rows: map[string]libovsdb.RowUpdate{
+			"uuid-ls": {Old: lswRow("renamed")},
+		}},
+	}})
+	assert.Equal(t, 0, len(odbi.GetLogicSwitches()), "test[%s]", "later updates applied directly")
+}
+
+// waitState returns the next state reported to ConnStateCB, failing the
+// test after timeout
+func waitState(t *testing.T, states chan ConnState, timeout time.Duration) ConnState {
+	select {
+	case state := <-states:
+		return state
+	case <-time.After(timeout):
+		t.Fatalf("no connection state change in %v", timeout)
+	}
+	return ConnState(-1)
+}
+
+// lswNames returns the sorted names of the cached logical switches
+func lswNames(api OVNDBApi) []string {
+	names := []string{}
+	for _, ls := range api.GetLogicSwitches() {
+		names = append(names, ls.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestReconnect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goovn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	server, err := ovsdbtest.NewNBServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	socket := filepath.Join(dir, OVNNB_SOCKET)
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+
+	states := make(chan ConnState, 16)
+	api, err := NewClient(Config{
+		Protocol:            UNIX,
+		Socket:              socket,
+		ReconnectMinBackoff: 10 * time.Millisecond,
+		ReconnectMaxBackoff: 40 * time.Millisecond,
+		ConnStateCB:         func(state ConnState) { states <- state },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err := api.LSWAdd("ls1")
+	assert.Nil(t, err, "test[%s]", "LSWAdd")
+	assert.Nil(t, api.Execute(cmd), "test[%s]", "execute LSWAdd")
+
+	// the server goes away, the client retries with backoff until the
+	// socket is back
+	l.Close()
+	server.CloseConnections()
+	assert.Equal(t, ConnStateDisconnected, waitState(t, states, time.Second), "test[%s]", "disconnected")
+	assert.NotNil(t, api.Execute(cmd), "test[%s]", "execute while disconnected")
+
+	// another client changes the database meanwhile
+	conn, err := newOVSDBConn(context.Background(), server.Pipe())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newNBImp(context.Background(), &ovnDBClient{dbclient: conn}, Config{DisableReconnect: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.close()
+	cmd, err = other.lswDelImp("ls1")
+	assert.Nil(t, err, "test[%s]", "lswDel")
+	assert.Nil(t, other.Execute(cmd), "test[%s]", "execute lswDel")
+	cmd, err = other.lswAddImp("ls2")
+	assert.Nil(t, err, "test[%s]", "lswAdd")
+	assert.Nil(t, other.Execute(cmd), "test[%s]", "execute lswAdd")
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case state := <-states:
+		t.Fatalf("connection state %v without a server", state)
+	default:
+	}
+
+	l, err = net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	// far below the default backoff of a second
+	assert.Equal(t, ConnStateConnected, waitState(t, states, 500*time.Millisecond), "test[%s]", "reconnected")
+	assert.Equal(t, []string{"ls2"}, lswNames(api), "test[%s]", "cache resynced")
+
+	// the client follows the updates of the new connection
+	cmd, err = other.lswAddImp("ls3")
+	assert.Nil(t, err, "test[%s]", "lswAdd")
+	assert.Nil(t, other.Execute(cmd), "test[%s]", "execute lswAdd")
+	deadline := time.Now().Add(time.Second)
+	for len(lswNames(api)) != 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"ls2", "ls3"}, lswNames(api), "test[%s]", "update after reconnect")
+
+	api.Close()
+	assert.Equal(t, ConnStateClosed, waitState(t, states, time.Second), "test[%s]", "closed")
+}
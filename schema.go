@@ -0,0 +1,551 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/unistack-org/libovsdb"
+)
+
+// atomic types of RFC 7047
+const (
+	typeInteger string = "integer"
+	typeReal    string = "real"
+	typeBoolean string = "boolean"
+	typeString  string = "string"
+	typeUUID    string = "uuid"
+)
+
+// baseType is the type of the keys or values of a column with its
+// constraints
+type baseType struct {
+	atomic string
+	// enum is nil when any value is allowed, atoms are in the JSON notation
+	enum       []interface{}
+	minInteger int64
+	maxInteger int64
+	minReal    float64
+	maxReal    float64
+	minLength  int
+	maxLength  int
+}
+
+// columnType is the type of a column in the schema, a set of key atoms or a
+// map from key to value atoms
+type columnType struct {
+	key   *baseType
+	value *baseType
+	min   int
+	// max is -1 when unlimited
+	max int
+}
+
+func (t *columnType) isMap() bool {
+	return t.value != nil
+}
+
+func parseColumnType(v interface{}) (*columnType, error) {
+	t := &columnType{min: 1, max: 1}
+	if _, ok := v.(string); ok {
+		key, err := parseBaseType(v)
+		t.key = key
+		return t, err
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid column type %v", v)
+	}
+	key, err := parseBaseType(obj["key"])
+	if err != nil {
+		return nil, err
+	}
+	t.key = key
+	if value, ok := obj["value"]; ok {
+		t.value, err = parseBaseType(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if min, ok := obj["min"].(float64); ok {
+		t.min = int(min)
+	}
+	switch max := obj["max"].(type) {
+	case float64:
+		t.max = int(max)
+	case string:
+		if max != "unlimited" {
+			return nil, fmt.Errorf("invalid max %s", max)
+		}
+		t.max = -1
+	}
+	return t, nil
+}
+
+// parseBaseType reads a base type, which is either the name of its atomic
+// type or an object with the type and its constraints
+func parseBaseType(v interface{}) (*baseType, error) {
+	b := &baseType{
+		minInteger: math.MinInt64,
+		maxInteger: math.MaxInt64,
+		minReal:    -math.MaxFloat64,
+		maxReal:    math.MaxFloat64,
+		maxLength:  math.MaxInt32,
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		obj = map[string]interface{}{"type": v}
+	}
+	switch atomic, _ := obj["type"].(string); atomic {
+	case typeInteger, typeReal, typeBoolean, typeString, typeUUID:
+		b.atomic = atomic
+	default:
+		return nil, fmt.Errorf("invalid atomic type %v", obj["type"])
+	}
+	if enum, ok := obj["enum"]; ok {
+		if set, ok := enum.([]interface{}); ok && len(set) == 2 && set[0] == "set" {
+			b.enum, _ = set[1].([]interface{})
+		} else {
+			b.enum = []interface{}{enum}
+		}
+	}
+	if n, ok := obj["minInteger"].(float64); ok {
+		b.minInteger = int64(n)
+	}
+	if n, ok := obj["maxInteger"].(float64); ok {
+		b.maxInteger = int64(n)
+	}
+	if n, ok := obj["minReal"].(float64); ok {
+		b.minReal = n
+	}
+	if n, ok := obj["maxReal"].(float64); ok {
+		b.maxReal = n
+	}
+	if n, ok := obj["minLength"].(float64); ok {
+		b.minLength = int(n)
+	}
+	if n, ok := obj["maxLength"].(float64); ok {
+		b.maxLength = int(n)
+	}
+	return b, nil
+}
+
+// setSchema keeps the schema of the database on conn, which is fetched when
+// it is connected
+func (odbi *ovnDBImp) setSchema(conn *ovsdbConn) {
+	odbi.cachemutex.Lock()
+	defer odbi.cachemutex.Unlock()
+	odbi.schema, _ = conn.Schema(odbi.db)
+}
+
+// SchemaVersion returns the version of the schema of the database, as
+// "5.16.0", to check for the features of the server
+func (odbi *ovnDBImp) SchemaVersion() string {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	return odbi.schema.Version
+}
+
+// getColumnType returns the type of column in table
+func (odbi *ovnDBImp) getColumnType(table, column string) (*columnType, error) {
+	odbi.cachemutex.RLock()
+	defer odbi.cachemutex.RUnlock()
+	if odbi.schema.Tables == nil {
+		return nil, fmt.Errorf("schema of database %s not found", odbi.db)
+	}
+	return schemaColumnType(odbi.schema, table, column)
+}
+
+func schemaColumnType(schema libovsdb.DatabaseSchema, table, column string) (*columnType, error) {
+	tableSchema, ok := schema.Tables[table]
+	if !ok {
+		return nil, fmt.Errorf("table %s not found in database %s", table, schema.Name)
+	}
+	if column == "_uuid" || column == "_version" {
+		return &columnType{key: &baseType{atomic: typeUUID}, min: 1, max: 1}, nil
+	}
+	columnSchema, ok := tableSchema.Columns[column]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in table %s", column, table)
+	}
+	return parseColumnType(columnSchema.Type)
+}
+
+// toAtom converts a Go value to an atom of the atomic type
+func toAtom(atomic string, v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch atomic {
+	case typeString:
+		if rv.Kind() == reflect.String {
+			return rv.String(), nil
+		}
+	case typeBoolean:
+		if rv.Kind() == reflect.Bool {
+			return rv.Bool(), nil
+		}
+	case typeInteger:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return int(rv.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int(rv.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			if f := rv.Float(); f == math.Trunc(f) {
+				return int(f), nil
+			}
+		}
+	case typeReal:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(rv.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(rv.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			return rv.Float(), nil
+		}
+	case typeUUID:
+		if uuid, ok := v.(libovsdb.UUID); ok {
+			return uuid, nil
+		}
+		if rv.Kind() == reflect.String {
+			return libovsdb.UUID{GoUUID: rv.String()}, nil
+		}
+	}
+	return nil, fmt.Errorf("%v is not a valid %s", v, atomic)
+}
+
+// toOvsdb converts a Go value to a value of the column type in the libovsdb
+// notation. A set is given as an atom or a slice, a map as a Go map, values
+// already in the libovsdb notation are kept. The number of elements is not
+// checked when relaxed, as for the argument of a mutation or a condition.
+func (t *columnType) toOvsdb(v interface{}, relaxed bool) (interface{}, error) {
+	var n int
+	var value interface{}
+	switch ov := v.(type) {
+	case libovsdb.OvsSet:
+		n, value = len(ov.GoSet), ov
+	case libovsdb.OvsMap:
+		n, value = len(ov.GoMap), ov
+		if n == 0 {
+			value = emptyMap()
+		}
+	default:
+		var err error
+		n, value, err = t.goToOvsdb(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !relaxed && (n < t.min || (t.max >= 0 && n > t.max)) {
+		max := "unlimited"
+		if t.max >= 0 {
+			max = fmt.Sprint(t.max)
+		}
+		return nil, fmt.Errorf("%d values is not in the range %d to %s", n, t.min, max)
+	}
+	return value, nil
+}
+
+func (t *columnType) goToOvsdb(v interface{}) (int, interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if t.isMap() {
+		if rv.Kind() != reflect.Map {
+			return 0, nil, fmt.Errorf("%v is not a map", v)
+		}
+		m := libovsdb.OvsMap{GoMap: make(map[interface{}]interface{}, rv.Len())}
+		for _, k := range rv.MapKeys() {
+			key, err := toAtom(t.key.atomic, k.Interface())
+			if err != nil {
+				return 0, nil, err
+			}
+			value, err := toAtom(t.value.atomic, rv.MapIndex(k).Interface())
+			if err != nil {
+				return 0, nil, err
+			}
+			m.GoMap[key] = value
+		}
+		if len(m.GoMap) == 0 {
+			return 0, emptyMap(), nil
+		}
+		return len(m.GoMap), m, nil
+	}
+	if rv.Kind() == reflect.Slice {
+		s := libovsdb.OvsSet{GoSet: make([]interface{}, 0, rv.Len())}
+		for i := 0; i < rv.Len(); i++ {
+			atom, err := toAtom(t.key.atomic, rv.Index(i).Interface())
+			if err != nil {
+				return 0, nil, err
+			}
+			s.GoSet = append(s.GoSet, atom)
+		}
+		return len(s.GoSet), s, nil
+	}
+	atom, err := toAtom(t.key.atomic, v)
+	return 1, atom, err
+}
+
+// emptyMap is an empty map in the libovsdb notation, an empty OvsMap being
+// marshaled as ["map", null]
+func emptyMap() interface{} {
+	return []interface{}{"map", []interface{}{}}
+}
+
+func isGoMap(v interface{}) bool {
+	return v != nil && reflect.TypeOf(v).Kind() == reflect.Map
+}
+
+// validateOperations checks the operations against the schema before they
+// are sent: the tables and columns they use and the type, size and
+// constraints of their values, as the server would.
+func validateOperations(schema libovsdb.DatabaseSchema, operations ...libovsdb.Operation) error {
+	for i, op := range operations {
+		err := validateOperation(schema, op)
+		if err != nil {
+			return fmt.Errorf("invalid operation %d (%s %s): %v", i, op.Op, op.Table, err)
+		}
+	}
+	return nil
+}
+
+func validateOperation(schema libovsdb.DatabaseSchema, op libovsdb.Operation) error {
+	if _, ok := schema.Tables[op.Table]; !ok {
+		return fmt.Errorf("table %s not found in database %s", op.Table, schema.Name)
+	}
+	for _, column := range op.Columns {
+		if _, err := schemaColumnType(schema, op.Table, column); err != nil {
+			return err
+		}
+	}
+	for column, value := range op.Row {
+		// the rows inserted or updated must be complete
+		if err := validateValue(schema, op.Table, column, value, checkFull); err != nil {
+			return err
+		}
+	}
+	for _, row := range op.Rows {
+		for column, value := range row {
+			if err := validateValue(schema, op.Table, column, value, checkType); err != nil {
+				return err
+			}
+		}
+	}
+	for _, condition := range op.Where {
+		c, err := jsonArray(condition, 3)
+		if err != nil {
+			return fmt.Errorf("invalid condition: %v", err)
+		}
+		column, _ := c[0].(string)
+		if err := validateValue(schema, op.Table, column, c[2], checkType); err != nil {
+			return err
+		}
+	}
+	for _, mutation := range op.Mutations {
+		m, err := jsonArray(mutation, 3)
+		if err != nil {
+			return fmt.Errorf("invalid mutation: %v", err)
+		}
+		column, _ := m[0].(string)
+		mutator, _ := m[1].(string)
+		t, err := schemaColumnType(schema, op.Table, column)
+		if err != nil {
+			return err
+		}
+		switch mutator {
+		case opInsert:
+			err = t.check(m[2], checkConstraints)
+		case opDelete:
+			if t.isMap() && !isJSONMap(m[2]) {
+				// the pairs of a set of keys are deleted
+				keys := &columnType{key: t.key, min: 0, max: -1}
+				err = keys.check(m[2], checkType)
+			} else {
+				err = t.check(m[2], checkType)
+			}
+		default:
+			// the argument of an arithmetic mutation is a single atom
+			scalar := &columnType{key: &baseType{atomic: t.key.atomic}, min: 1, max: 1}
+			err = scalar.check(m[2], checkFull)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value of column %s: %v", column, err)
+		}
+	}
+	return nil
+}
+
+// how much of a value is checked: the type of its atoms, the constraints of
+// the atoms, and their number
+const (
+	checkType = iota
+	checkConstraints
+	checkFull
+)
+
+func validateValue(schema libovsdb.DatabaseSchema, table, column string, value interface{}, level int) error {
+	t, err := schemaColumnType(schema, table, column)
+	if err != nil {
+		return err
+	}
+	err = t.check(value, level)
+	if err != nil {
+		return fmt.Errorf("invalid value of column %s: %v", column, err)
+	}
+	return nil
+}
+
+// toJSON returns v in the JSON notation of RFC 7047, whatever the libovsdb
+// notation used to build it
+func toJSON(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var j interface{}
+	err = json.Unmarshal(b, &j)
+	return j, err
+}
+
+// jsonArray returns the elements of v, which must be an array of n
+func jsonArray(v interface{}, n int) ([]interface{}, error) {
+	j, err := toJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := j.([]interface{})
+	if !ok || len(a) != n {
+		return nil, fmt.Errorf("%v is not an array of %d elements", j, n)
+	}
+	return a, nil
+}
+
+func isJSONMap(v interface{}) bool {
+	j, err := toJSON(v)
+	if err != nil {
+		return false
+	}
+	a, ok := j.([]interface{})
+	return ok && len(a) == 2 && a[0] == "map"
+}
+
+// check checks a value of the column in any libovsdb notation
+func (t *columnType) check(v interface{}, level int) error {
+	j, err := toJSON(v)
+	if err != nil {
+		return err
+	}
+	var keys, values []interface{}
+	a, _ := j.([]interface{})
+	switch {
+	case t.isMap():
+		if len(a) != 2 || a[0] != "map" {
+			return fmt.Errorf("%v is not a map", j)
+		}
+		pairs, ok := a[1].([]interface{})
+		if !ok {
+			return fmt.Errorf("%v is not a map", j)
+		}
+		for _, p := range pairs {
+			pair, ok := p.([]interface{})
+			if !ok || len(pair) != 2 {
+				return fmt.Errorf("invalid pair %v", p)
+			}
+			keys = append(keys, pair[0])
+			values = append(values, pair[1])
+		}
+	case len(a) == 2 && a[0] == "set":
+		elements, ok := a[1].([]interface{})
+		if !ok {
+			return fmt.Errorf("%v is not a set", j)
+		}
+		keys = elements
+	default:
+		keys = []interface{}{j}
+	}
+	if level == checkFull && (len(keys) < t.min || (t.max >= 0 && len(keys) > t.max)) {
+		max := "unlimited"
+		if t.max >= 0 {
+			max = fmt.Sprint(t.max)
+		}
+		return fmt.Errorf("%d values is not in the range %d to %s", len(keys), t.min, max)
+	}
+	for i, key := range keys {
+		if err := t.key.check(key, level); err != nil {
+			return err
+		}
+		if t.isMap() {
+			if err := t.value.check(values[i], level); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// check checks an atom in the JSON notation
+func (b *baseType) check(atom interface{}, level int) error {
+	switch b.atomic {
+	case typeInteger:
+		n, ok := atom.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%v is not an integer", atom)
+		}
+		if level >= checkConstraints && (int64(n) < b.minInteger || int64(n) > b.maxInteger) {
+			return fmt.Errorf("%v is not in the range %d to %d", atom, b.minInteger, b.maxInteger)
+		}
+	case typeReal:
+		n, ok := atom.(float64)
+		if !ok {
+			return fmt.Errorf("%v is not a real", atom)
+		}
+		if level >= checkConstraints && (n < b.minReal || n > b.maxReal) {
+			return fmt.Errorf("%v is not in the range %g to %g", atom, b.minReal, b.maxReal)
+		}
+	case typeBoolean:
+		if _, ok := atom.(bool); !ok {
+			return fmt.Errorf("%v is not a boolean", atom)
+		}
+	case typeString:
+		s, ok := atom.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a string", atom)
+		}
+		if n := utf8.RuneCountInString(s); level >= checkConstraints && (n < b.minLength || n > b.maxLength) {
+			return fmt.Errorf("%q is not between %d and %d characters long", s, b.minLength, b.maxLength)
+		}
+	case typeUUID:
+		a, ok := atom.([]interface{})
+		if !ok || len(a) != 2 || (a[0] != "uuid" && a[0] != "named-uuid") {
+			return fmt.Errorf("%v is not a uuid", atom)
+		}
+		if _, ok := a[1].(string); !ok {
+			return fmt.Errorf("%v is not a uuid", atom)
+		}
+	}
+	if level >= checkConstraints && b.enum != nil {
+		for _, e := range b.enum {
+			if reflect.DeepEqual(e, atom) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%v is not one of the allowed values %v", atom, b.enum)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package goovn
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ebay/go-ovn/ovsdbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/unistack-org/libovsdb"
+)
+
+func TestValidateOperations(t *testing.T) {
+	var schema libovsdb.DatabaseSchema
+	if err := json.Unmarshal([]byte(ovsdbtest.NBSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	ids, _ := libovsdb.NewOvsMap(map[string]string{"k": "v"})
+	ports, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUUID: "rowport"}})
+	keys, _ := libovsdb.NewOvsSet([]string{"k"})
+	byName := libovsdb.NewCondition("name", "==", "ls1")
+
+	valid := []libovsdb.Operation{
+		{Op: opInsert, Table: tableACL, Row: OVNRow{"action": "drop", "priority": 1001, "direction": "to-lport",
+			"match": "ip4", "external_ids": ids, "log": false}},
+		{Op: opUpdate, Table: tableLogicalSwitchPort, Row: OVNRow{"dhcpv6_options": libovsdb.UUID{GoUUID: "4c2f7dd3-5ba2-46cd-9d2f-9b1f4a8e57a1"}},
+			Where: []interface{}{byName}},
+		{Op: opMutate, Table: tableLogicalSwitch, Where: []interface{}{byName}, Mutations: []interface{}{
+			libovsdb.NewMutation("ports", opInsert, ports),
+			libovsdb.NewMutation("external_ids", opDelete, keys),
+			libovsdb.NewMutation("external_ids", opDelete, ids),
+		}},
+		{Op: opMutate, Table: tableMeterBand, Where: []interface{}{}, Mutations: []interface{}{
+			libovsdb.NewMutation("rate", "*=", 0),
+		}},
+		{Op: opSelect, Table: tableLogicalSwitch, Where: []interface{}{byName}, Columns: []string{"_uuid", "name"}},
+	}
+	assert.Nil(t, validateOperations(schema, valid...), "test[%s]", "valid operations")
+
+	invalid := map[string]libovsdb.Operation{
+		"unknown table":      {Op: opSelect, Table: "Missing", Where: []interface{}{}},
+		"unknown column":     {Op: opInsert, Table: tableLogicalSwitch, Row: OVNRow{"nmae": "ls1"}},
+		"unknown condition":  {Op: opSelect, Table: tableLogicalSwitch, Where: []interface{}{libovsdb.NewCondition("nmae", "==", "ls1")}},
+		"unknown selection":  {Op: opSelect, Table: tableLogicalSwitch, Where: []interface{}{}, Columns: []string{"nmae"}},
+		"string for uuid":    {Op: opUpdate, Table: tableLogicalSwitchPort, Row: OVNRow{"dhcpv6_options": "4c2f7dd3"}, Where: []interface{}{byName}},
+		"string for integer": {Op: opInsert, Table: tableACL, Row: OVNRow{"priority": "1001"}},
+		"enum":               {Op: opInsert, Table: tableACL, Row: OVNRow{"action": "accept"}},
+		"integer range":      {Op: opInsert, Table: tableACL, Row: OVNRow{"priority": 32768}},
+		"too many values":    {Op: opInsert, Table: tableACL, Row: OVNRow{"meter": []string{"m1", "m2"}}},
+		"too few values":     {Op: opInsert, Table: tableLogicalSwitch, Row: OVNRow{"name": libovsdb.OvsSet{GoSet: []interface{}{}}}},
+		"set for map":        {Op: opInsert, Table: tableLogicalSwitch, Row: OVNRow{"external_ids": keys}},
+		"mutation type": {Op: opMutate, Table: tableLogicalSwitch, Where: []interface{}{}, Mutations: []interface{}{
+			libovsdb.NewMutation("ports", opInsert, keys),
+		}},
+	}
+	for name, op := range invalid {
+		assert.NotNil(t, validateOperations(schema, op), "test[%s]", name)
+	}
+
+	// LSPSetDHCPv6Options used to insert the uuid as a string
+	stringOp := libovsdb.Operation{Op: opMutate, Table: tableLogicalSwitchPort, Where: []interface{}{byName}, Mutations: []interface{}{
+		libovsdb.NewMutation("dhcpv6_options", opInsert, "4c2f7dd3-5ba2-46cd-9d2f-9b1f4a8e57a1"),
+	}}
+	assert.NotNil(t, validateOperations(schema, stringOp), "test[%s]", "string in uuid set")
+	cmd, err := ovndbapi.LSPSetDHCPv6Options("lsp1", "4c2f7dd3-5ba2-46cd-9d2f-9b1f4a8e57a1")
+	assert.Nil(t, err, "test[%s]", "set dhcpv6 options")
+	assert.Nil(t, validateOperations(schema, cmd.Operations...), "test[%s]", "set dhcpv6 options")
+
+	version := ovndbapi.SchemaVersion()
+	assert.Regexp(t, `^\d+\.\d+\.\d+$`, version, "test[%s]", "schema version")
+}
@@ -0,0 +1,90 @@
+/**
+ * Copyright (c) 2017 eBay Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package goovn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// tlsConfig returns the configuration used to dial the ssl remote host.
+// Certificate files are read at every call, so rotated certificates are
+// picked up by the next connection without losing the cache.
+func (client *ovnDBClient) tlsConfig(host string) (*tls.Config, error) {
+	var config *tls.Config
+	switch {
+	case client.tls != nil:
+		config = client.tls.Clone()
+	case client.certFile != "" || client.caFile != "":
+		config = &tls.Config{}
+		if client.certFile != "" {
+			cert, err := tls.LoadX509KeyPair(client.certFile, client.keyFile)
+			if err != nil {
+				return nil, err
+			}
+			config.Certificates = []tls.Certificate{cert}
+		}
+		if client.caFile != "" {
+			pem, err := ioutil.ReadFile(client.caFile)
+			if err != nil {
+				return nil, err
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificate found in %s", client.caFile)
+			}
+			config.RootCAs = pool
+		}
+	default:
+		return envTLSConfig()
+	}
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+	return config, nil
+}
+
+// envTLSConfig is the configuration libovsdb used to build from the
+// environment: CLIENT_CERT_CA_CERT holds the client certificate followed by
+// the ca certificate and CLIENT_PRIVKEY the key. The server certificate is
+// not verified against its name.
+//
+// Deprecated: set TLSConfig or CertFile, KeyFile and CAFile in Config.
+func envTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(os.Getenv("CLIENT_CERT_CA_CERT"), os.Getenv("CLIENT_PRIVKEY"))
+	if err != nil {
+		return nil, err
+	}
+	if len(cert.Certificate) != 2 {
+		return nil, errors.New("CLIENT_CERT_CA_CERT should have 2 concatenated certificates: client + CA")
+	}
+	ca, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		RootCAs:            pool,
+		InsecureSkipVerify: true,
+	}, nil
+}
@@ -0,0 +1,272 @@
+package goovn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ebay/go-ovn/ovsdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLSConfig(t *testing.T) {
+	shared := &tls.Config{}
+	client := &ovnDBClient{tls: shared}
+	config, err := client.tlsConfig("nb1.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "nb1.example.com", config.ServerName, "test[%s]", "server name defaults to remote host")
+	assert.Equal(t, "", shared.ServerName, "test[%s]", "given config not modified")
+
+	client = &ovnDBClient{tls: &tls.Config{ServerName: "ovn-nb"}}
+	config, err = client.tlsConfig("nb1.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "ovn-nb", config.ServerName, "test[%s]", "explicit server name kept")
+
+	ca, err := ioutil.TempFile("", "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(ca.Name())
+	ca.WriteString("not a certificate")
+	ca.Close()
+
+	client = &ovnDBClient{caFile: ca.Name()}
+	_, err = client.tlsConfig("nb1.example.com")
+	assert.Equal(t, true, err != nil, "test[%s]", "invalid ca file rejected")
+
+	client = &ovnDBClient{certFile: "/nonexistent/cert.pem", keyFile: "/nonexistent/key.pem"}
+	_, err = client.tlsConfig("nb1.example.com")
+	assert.Equal(t, true, err != nil, "test[%s]", "missing cert file rejected")
+}
+
+// testCA issues the certificates of the TLS tests
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	pem    []byte
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{
+		cert:   cert,
+		key:    key,
+		pem:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		serial: 1,
+	}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue returns the PEM certificate and key of cn, valid for the given
+// server names
+func (ca *testCA) issue(t *testing.T, cn string, hosts ...string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// tlsServer serves an in-memory northbound database over TLS on the local
+// host, requiring a client certificate of ca. The common name of every
+// client certificate presented is sent to clients.
+func tlsServer(t *testing.T, ca *testCA) (*ovsdbtest.Server, int, chan string) {
+	server, err := ovsdbtest.NewNBServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM := ca.issue(t, "ovn-nb", "ovn-nb.test", "127.0.0.1")
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients := make(chan string, 16)
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.pool(),
+		VerifyPeerCertificate: func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+			clients <- chains[0][0].Subject.CommonName
+			return nil
+		},
+	}
+	l, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	return server, l.Addr().(*net.TCPAddr).Port, clients
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	err := ioutil.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLSServerName(t *testing.T) {
+	ca := newTestCA(t)
+	server, port, _ := tlsServer(t, ca)
+	defer server.Close()
+	certPEM, keyPEM := ca.issue(t, "client")
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		serverName string
+		ok         bool
+	}{
+		{"server name defaults to the remote address", "", true},
+		{"server name of the certificate", "ovn-nb.test", true},
+		{"server name not in the certificate", "ovn-sb.test", false},
+	}
+	for _, test := range tests {
+		api, err := NewClient(Config{
+			Protocol:         SSL,
+			Server:           "127.0.0.1",
+			Port:             port,
+			DisableReconnect: true,
+			TLSConfig: &tls.Config{
+				Certificates: []tls.Certificate{cert},
+				RootCAs:      ca.pool(),
+				ServerName:   test.serverName,
+			},
+		})
+		assert.Equal(t, test.ok, err == nil, "test[%s]: %v", test.name, err)
+		if err == nil {
+			api.Close()
+		}
+	}
+
+	// a server certificate of another ca
+	_, err = NewClient(Config{
+		Protocol:         SSL,
+		Server:           "127.0.0.1",
+		Port:             port,
+		DisableReconnect: true,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      newTestCA(t).pool(),
+		},
+	})
+	assert.NotNil(t, err, "test[%s]", "unknown authority rejected")
+}
+
+func TestTLSCertFiles(t *testing.T) {
+	ca := newTestCA(t)
+	server, port, clients := tlsServer(t, ca)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "goovn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeFile(t, caFile, ca.pem)
+	certPEM, keyPEM := ca.issue(t, "client-1")
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+
+	states := make(chan ConnState, 16)
+	api, err := NewClient(Config{
+		Protocol:            SSL,
+		Server:              "127.0.0.1",
+		Port:                port,
+		CAFile:              caFile,
+		CertFile:            certFile,
+		KeyFile:             keyFile,
+		ReconnectMinBackoff: 10 * time.Millisecond,
+		ConnStateCB:         func(state ConnState) { states <- state },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer api.Close()
+	assert.Equal(t, "client-1", <-clients, "test[%s]", "handshake with the cert files")
+
+	// the rotated certificate is presented on the next connection
+	certPEM, keyPEM = ca.issue(t, "client-2")
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	server.CloseConnections()
+	assert.Equal(t, ConnStateDisconnected, waitState(t, states, time.Second), "test[%s]", "disconnected")
+	assert.Equal(t, ConnStateConnected, waitState(t, states, time.Second), "test[%s]", "reconnected")
+	assert.Equal(t, "client-2", <-clients, "test[%s]", "cert files read again on reconnect")
+
+	cmd, err := api.LSWAdd(LSW)
+	assert.Nil(t, err, "test[%s]", "LSWAdd")
+	assert.Nil(t, api.Execute(cmd), "test[%s]", "execute over tls")
+}